@@ -1,8 +1,14 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 	"uptime-monitor/internal/auth"
 	"uptime-monitor/internal/config"
 	"uptime-monitor/internal/database"
@@ -30,14 +36,14 @@ func main() {
 	// Initialize authentication service
 	authService := auth.NewService(cfg.Auth.JWTSecret)
 
-	// Initialize monitoring system
-	monitorManager := monitoring.NewManager(db)
-	go monitorManager.Start()
-
 	// Initialize WebSocket hub
 	wsHub := websocket.NewHub()
 	go wsHub.Run()
 
+	// Initialize monitoring system
+	monitorManager := monitoring.NewManager(db, wsHub, cfg.Monitor)
+	go monitorManager.Start()
+
 	// Setup router
 	router := gin.Default()
 
@@ -127,8 +133,27 @@ func main() {
 	})
 
 	// Start server
-	log.Printf("Server starting on port %s", cfg.Server.Port)
-	if err := router.Run(":" + cfg.Server.Port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	server := &http.Server{Addr: ":" + cfg.Server.Port, Handler: router}
+	go func() {
+		log.Printf("Server starting on port %s", cfg.Server.Port)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal("Failed to start server:", err)
+		}
+	}()
+
+	// On SIGINT or SIGTERM stop taking requests, let running checks and
+	// notifications finish, close WebSocket clients and then the database
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-signals
+	log.Printf("Received %s, shutting down", sig)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout)*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("HTTP server shutdown: %v", err)
 	}
+	monitorManager.Stop(ctx)
+	wsHub.Close(ctx)
 }
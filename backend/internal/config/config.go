@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 )
 
 type Config struct {
@@ -13,8 +14,9 @@ type Config struct {
 }
 
 type ServerConfig struct {
-	Port string
-	Host string
+	Port            string
+	Host            string
+	ShutdownTimeout int // seconds allowed for a graceful shutdown
 }
 
 type DatabaseConfig struct {
@@ -35,13 +37,19 @@ type MonitorConfig struct {
 	CheckInterval int // seconds
 	Timeout       int // seconds
 	MaxRetries    int
+	SSLExpiryDays []int  // days before certificate expiry to send ssl_expiring
+	ExecEnabled   bool   // allow exec monitors to run local commands
+	ExecPath      string // PATH given to exec monitor commands
+	ProxyURL      string // default proxy for HTTP checks; empty uses the environment
+	Workers       int    // checks run at the same time
 }
 
 func Load() (*Config, error) {
 	cfg := &Config{
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8080"),
-			Host: getEnv("HOST", "0.0.0.0"),
+			Port:            getEnv("PORT", "8080"),
+			Host:            getEnv("HOST", "0.0.0.0"),
+			ShutdownTimeout: getEnvInt("SHUTDOWN_TIMEOUT", 30),
 		},
 		Database: DatabaseConfig{
 			Type:     getEnv("DB_TYPE", "sqlite"),
@@ -59,6 +67,11 @@ func Load() (*Config, error) {
 			CheckInterval: getEnvInt("MONITOR_INTERVAL", 60),
 			Timeout:       getEnvInt("MONITOR_TIMEOUT", 30),
 			MaxRetries:    getEnvInt("MONITOR_RETRIES", 3),
+			SSLExpiryDays: getEnvIntList("SSL_EXPIRY_DAYS", []int{30, 14, 7}),
+			ExecEnabled:   getEnvBool("EXEC_MONITORS", false),
+			ExecPath:      getEnv("EXEC_PATH", "/usr/local/libexec/nagios:/usr/local/bin:/usr/bin:/bin"),
+			ProxyURL:      getEnv("MONITOR_PROXY", ""),
+			Workers:       getEnvInt("MONITOR_WORKERS", 50),
 		},
 	}
 
@@ -80,3 +93,29 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
+func getEnvIntList(key string, defaultValue []int) []int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var list []int
+	for _, part := range strings.Split(value, ",") {
+		i, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return defaultValue
+		}
+		list = append(list, i)
+	}
+	return list
+}
@@ -54,7 +54,141 @@ func runMigrations(db *sqlx.DB, dbType string) error {
 	}
 
 	// Run migrations for existing tables
-	return migrateNotificationChannels(db, dbType)
+	if err := migrateNotificationChannels(db, dbType); err != nil {
+		return err
+	}
+
+	return migrateColumns(db, dbType)
+}
+
+// columnMigration describes a column added after the initial schema
+type columnMigration struct {
+	table      string
+	column     string
+	definition string
+}
+
+// columnMigrations lists columns added to existing tables, in order. New
+// installs get them from the schema; older databases are altered in place.
+var columnMigrations = []columnMigration{
+	{"monitors", "tags", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "retry_interval", "INTEGER NOT NULL DEFAULT 20"},
+	{"monitors", "keyword", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "keyword_regex", "BOOLEAN NOT NULL DEFAULT false"},
+	{"monitors", "keyword_invert", "BOOLEAN NOT NULL DEFAULT false"},
+	{"monitors", "max_body_bytes", "INTEGER NOT NULL DEFAULT 0"},
+	{"monitors", "http_mode", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "json_assertions", "TEXT NOT NULL DEFAULT '[]'"},
+	{"monitors", "method", "TEXT NOT NULL DEFAULT 'GET'"},
+	{"monitors", "headers", "TEXT NOT NULL DEFAULT '{}'"},
+	{"monitors", "body", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "auth_method", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "auth_username", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "auth_password", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "auth_token", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "accepted_status_codes", "TEXT NOT NULL DEFAULT '200-399'"},
+	{"monitors", "follow_redirects", "BOOLEAN NOT NULL DEFAULT true"},
+	{"monitors", "max_redirects", "INTEGER NOT NULL DEFAULT 10"},
+	{"monitors", "dns_resolver", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "dns_record_type", "TEXT NOT NULL DEFAULT 'A'"},
+	{"monitors", "dns_expected", "TEXT NOT NULL DEFAULT '[]'"},
+	{"monitors", "push_token", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "push_grace_period", "INTEGER NOT NULL DEFAULT 60"},
+	{"monitors", "last_push_at", "TIMESTAMP"},
+	{"monitors", "tcp_send", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "tls_mode", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "ping_count", "INTEGER NOT NULL DEFAULT 3"},
+	{"monitors", "ping_max_loss", "REAL"},
+	{"monitors", "ping_max_rtt", "INTEGER NOT NULL DEFAULT 0"},
+	{"monitors", "ping_max_jitter", "INTEGER NOT NULL DEFAULT 0"},
+	{"monitors", "ping_privileged", "BOOLEAN NOT NULL DEFAULT false"},
+	{"monitors", "address_family", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "db_query", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "grpc_service", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "exec_args", "TEXT NOT NULL DEFAULT '[]'"},
+	{"monitors", "smtp_from", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "smtp_to", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "transaction_steps", "TEXT NOT NULL DEFAULT '[]'"},
+	{"monitors", "content_ignore_regex", "TEXT NOT NULL DEFAULT '[]'"},
+	{"monitors", "content_ignore_selectors", "TEXT NOT NULL DEFAULT '[]'"},
+	{"monitors", "content_hash", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "content_snapshot", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "proxy_url", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "tls_client_cert", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "tls_client_key", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "tls_ca_bundle", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "tls_ignore_errors", "BOOLEAN NOT NULL DEFAULT false"},
+	{"monitor_checks", "cert_expires_at", "TIMESTAMP"},
+	{"monitor_checks", "cert_issuer", "TEXT NOT NULL DEFAULT ''"},
+	{"monitor_checks", "cert_sans", "TEXT NOT NULL DEFAULT ''"},
+	{"monitor_checks", "cert_days_remaining", "INTEGER"},
+	{"monitor_checks", "ping_min", "REAL"},
+	{"monitor_checks", "ping_avg", "REAL"},
+	{"monitor_checks", "ping_max", "REAL"},
+	{"monitor_checks", "ping_stddev", "REAL"},
+	{"monitor_checks", "packet_loss", "REAL"},
+	{"monitor_checks", "metrics", "TEXT NOT NULL DEFAULT '[]'"},
+	{"monitor_checks", "steps", "TEXT NOT NULL DEFAULT '[]'"},
+	{"monitor_checks", "dns_time", "REAL"},
+	{"monitor_checks", "connect_time", "REAL"},
+	{"monitor_checks", "tls_time", "REAL"},
+	{"monitor_checks", "ttfb", "REAL"},
+	{"monitor_checks", "transfer_time", "REAL"},
+	{"monitor_checks", "content_hash", "TEXT NOT NULL DEFAULT ''"},
+	{"monitor_checks", "content_diff", "TEXT NOT NULL DEFAULT ''"},
+	{"monitor_checks", "families", "TEXT NOT NULL DEFAULT '[]'"},
+}
+
+// migrateColumns adds any missing columns from columnMigrations
+func migrateColumns(db *sqlx.DB, dbType string) error {
+	for _, m := range columnMigrations {
+		if dbType == "postgres" {
+			_, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s", m.table, m.column, m.definition))
+			if err != nil {
+				return fmt.Errorf("failed to add column %s.%s: %v", m.table, m.column, err)
+			}
+			continue
+		}
+
+		exists, err := sqliteColumnExists(db, m.table, m.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", m.table, m.column, m.definition))
+		if err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %v", m.table, m.column, err)
+		}
+	}
+
+	return nil
+}
+
+// sqliteColumnExists checks the table pragma for a column
+func sqliteColumnExists(db *sqlx.DB, table, column string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid int
+		var name, colType string
+		var notNull, pk int
+		var defaultValue interface{}
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+
+	return false, rows.Err()
 }
 
 // migrateNotificationChannels handles migration from old schema to new Shoutrrr-based schema
@@ -216,6 +350,53 @@ CREATE TABLE IF NOT EXISTS monitors (
     timeout INTEGER DEFAULT 30,
     max_retries INTEGER DEFAULT 3,
     active BOOLEAN DEFAULT true,
+    tags TEXT NOT NULL DEFAULT '',
+    retry_interval INTEGER NOT NULL DEFAULT 20,
+    keyword TEXT NOT NULL DEFAULT '',
+    keyword_regex BOOLEAN NOT NULL DEFAULT false,
+    keyword_invert BOOLEAN NOT NULL DEFAULT false,
+    max_body_bytes INTEGER NOT NULL DEFAULT 0,
+    http_mode TEXT NOT NULL DEFAULT '',
+    json_assertions TEXT NOT NULL DEFAULT '[]',
+    method TEXT NOT NULL DEFAULT 'GET',
+    headers TEXT NOT NULL DEFAULT '{}',
+    body TEXT NOT NULL DEFAULT '',
+    auth_method TEXT NOT NULL DEFAULT '',
+    auth_username TEXT NOT NULL DEFAULT '',
+    auth_password TEXT NOT NULL DEFAULT '',
+    auth_token TEXT NOT NULL DEFAULT '',
+    accepted_status_codes TEXT NOT NULL DEFAULT '200-399',
+    follow_redirects BOOLEAN NOT NULL DEFAULT true,
+    max_redirects INTEGER NOT NULL DEFAULT 10,
+    dns_resolver TEXT NOT NULL DEFAULT '',
+    dns_record_type TEXT NOT NULL DEFAULT 'A',
+    dns_expected TEXT NOT NULL DEFAULT '[]',
+    push_token TEXT NOT NULL DEFAULT '',
+    push_grace_period INTEGER NOT NULL DEFAULT 60,
+    last_push_at TIMESTAMP,
+    tcp_send TEXT NOT NULL DEFAULT '',
+    tls_mode TEXT NOT NULL DEFAULT '',
+    ping_count INTEGER NOT NULL DEFAULT 3,
+    ping_max_loss REAL,
+    ping_max_rtt INTEGER NOT NULL DEFAULT 0,
+    ping_max_jitter INTEGER NOT NULL DEFAULT 0,
+    ping_privileged BOOLEAN NOT NULL DEFAULT false,
+    address_family TEXT NOT NULL DEFAULT '',
+    db_query TEXT NOT NULL DEFAULT '',
+    grpc_service TEXT NOT NULL DEFAULT '',
+    exec_args TEXT NOT NULL DEFAULT '[]',
+    smtp_from TEXT NOT NULL DEFAULT '',
+    smtp_to TEXT NOT NULL DEFAULT '',
+    transaction_steps TEXT NOT NULL DEFAULT '[]',
+    content_ignore_regex TEXT NOT NULL DEFAULT '[]',
+    content_ignore_selectors TEXT NOT NULL DEFAULT '[]',
+    content_hash TEXT NOT NULL DEFAULT '',
+    content_snapshot TEXT NOT NULL DEFAULT '',
+    proxy_url TEXT NOT NULL DEFAULT '',
+    tls_client_cert TEXT NOT NULL DEFAULT '',
+    tls_client_key TEXT NOT NULL DEFAULT '',
+    tls_ca_bundle TEXT NOT NULL DEFAULT '',
+    tls_ignore_errors BOOLEAN NOT NULL DEFAULT false,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
@@ -228,6 +409,25 @@ CREATE TABLE IF NOT EXISTS monitor_checks (
     status_code INTEGER,
     message TEXT,
     checked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    cert_expires_at TIMESTAMP,
+    cert_issuer TEXT NOT NULL DEFAULT '',
+    cert_sans TEXT NOT NULL DEFAULT '',
+    cert_days_remaining INTEGER,
+    ping_min REAL,
+    ping_avg REAL,
+    ping_max REAL,
+    ping_stddev REAL,
+    packet_loss REAL,
+    metrics TEXT NOT NULL DEFAULT '[]',
+    steps TEXT NOT NULL DEFAULT '[]',
+    dns_time REAL,
+    connect_time REAL,
+    tls_time REAL,
+    ttfb REAL,
+    transfer_time REAL,
+    content_hash TEXT NOT NULL DEFAULT '',
+    content_diff TEXT NOT NULL DEFAULT '',
+    families TEXT NOT NULL DEFAULT '[]',
     FOREIGN KEY (monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
 );
 
@@ -287,6 +487,53 @@ CREATE TABLE IF NOT EXISTS monitors (
     timeout INTEGER DEFAULT 30,
     max_retries INTEGER DEFAULT 3,
     active BOOLEAN DEFAULT true,
+    tags TEXT NOT NULL DEFAULT '',
+    retry_interval INTEGER NOT NULL DEFAULT 20,
+    keyword TEXT NOT NULL DEFAULT '',
+    keyword_regex BOOLEAN NOT NULL DEFAULT false,
+    keyword_invert BOOLEAN NOT NULL DEFAULT false,
+    max_body_bytes INTEGER NOT NULL DEFAULT 0,
+    http_mode TEXT NOT NULL DEFAULT '',
+    json_assertions TEXT NOT NULL DEFAULT '[]',
+    method TEXT NOT NULL DEFAULT 'GET',
+    headers TEXT NOT NULL DEFAULT '{}',
+    body TEXT NOT NULL DEFAULT '',
+    auth_method TEXT NOT NULL DEFAULT '',
+    auth_username TEXT NOT NULL DEFAULT '',
+    auth_password TEXT NOT NULL DEFAULT '',
+    auth_token TEXT NOT NULL DEFAULT '',
+    accepted_status_codes TEXT NOT NULL DEFAULT '200-399',
+    follow_redirects BOOLEAN NOT NULL DEFAULT true,
+    max_redirects INTEGER NOT NULL DEFAULT 10,
+    dns_resolver TEXT NOT NULL DEFAULT '',
+    dns_record_type TEXT NOT NULL DEFAULT 'A',
+    dns_expected TEXT NOT NULL DEFAULT '[]',
+    push_token TEXT NOT NULL DEFAULT '',
+    push_grace_period INTEGER NOT NULL DEFAULT 60,
+    last_push_at TIMESTAMP,
+    tcp_send TEXT NOT NULL DEFAULT '',
+    tls_mode TEXT NOT NULL DEFAULT '',
+    ping_count INTEGER NOT NULL DEFAULT 3,
+    ping_max_loss REAL,
+    ping_max_rtt INTEGER NOT NULL DEFAULT 0,
+    ping_max_jitter INTEGER NOT NULL DEFAULT 0,
+    ping_privileged BOOLEAN NOT NULL DEFAULT false,
+    address_family TEXT NOT NULL DEFAULT '',
+    db_query TEXT NOT NULL DEFAULT '',
+    grpc_service TEXT NOT NULL DEFAULT '',
+    exec_args TEXT NOT NULL DEFAULT '[]',
+    smtp_from TEXT NOT NULL DEFAULT '',
+    smtp_to TEXT NOT NULL DEFAULT '',
+    transaction_steps TEXT NOT NULL DEFAULT '[]',
+    content_ignore_regex TEXT NOT NULL DEFAULT '[]',
+    content_ignore_selectors TEXT NOT NULL DEFAULT '[]',
+    content_hash TEXT NOT NULL DEFAULT '',
+    content_snapshot TEXT NOT NULL DEFAULT '',
+    proxy_url TEXT NOT NULL DEFAULT '',
+    tls_client_cert TEXT NOT NULL DEFAULT '',
+    tls_client_key TEXT NOT NULL DEFAULT '',
+    tls_ca_bundle TEXT NOT NULL DEFAULT '',
+    tls_ignore_errors BOOLEAN NOT NULL DEFAULT false,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
@@ -299,6 +546,25 @@ CREATE TABLE IF NOT EXISTS monitor_checks (
     status_code INTEGER,
     message TEXT,
     checked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    cert_expires_at TIMESTAMP,
+    cert_issuer TEXT NOT NULL DEFAULT '',
+    cert_sans TEXT NOT NULL DEFAULT '',
+    cert_days_remaining INTEGER,
+    ping_min REAL,
+    ping_avg REAL,
+    ping_max REAL,
+    ping_stddev REAL,
+    packet_loss REAL,
+    metrics TEXT NOT NULL DEFAULT '[]',
+    steps TEXT NOT NULL DEFAULT '[]',
+    dns_time REAL,
+    connect_time REAL,
+    tls_time REAL,
+    ttfb REAL,
+    transfer_time REAL,
+    content_hash TEXT NOT NULL DEFAULT '',
+    content_diff TEXT NOT NULL DEFAULT '',
+    families TEXT NOT NULL DEFAULT '[]',
     FOREIGN KEY (monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
 );
 
@@ -418,36 +418,9 @@ func deleteUser(db *sqlx.DB) gin.HandlerFunc {
 // Middleware to require authentication
 func authRequired(authService *auth.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			c.Abort()
+		if !authenticate(c, authService) {
 			return
 		}
-
-		// Extract token from "Bearer <token>"
-		tokenString := ""
-		if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
-			tokenString = authHeader[7:]
-		} else {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
-			c.Abort()
-			return
-		}
-
-		// Validate token
-		claims, err := authService.ValidateToken(tokenString)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
-			return
-		}
-
-		// Add user info to context
-		c.Set("user_id", (*claims)["user_id"])
-		c.Set("username", (*claims)["username"])
-		c.Set("role", (*claims)["role"])
-
 		c.Next()
 	}
 }
@@ -455,12 +428,61 @@ func authRequired(authService *auth.Service) gin.HandlerFunc {
 // Middleware to require admin role
 func adminRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role, exists := c.Get("role")
-		if !exists || role != "admin" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
-			c.Abort()
+		if !isAdmin(c) {
 			return
 		}
 		c.Next()
 	}
 }
+
+// requireAdmin does the checks of authRequired and adminRequired inside a
+// handler, for routes where only some requests need an admin
+func requireAdmin(c *gin.Context, authService *auth.Service) bool {
+	return authenticate(c, authService) && isAdmin(c)
+}
+
+// authenticate validates the bearer token and adds the user to the context,
+// or aborts the request
+func authenticate(c *gin.Context, authService *auth.Service) bool {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+		c.Abort()
+		return false
+	}
+
+	// Extract token from "Bearer <token>"
+	tokenString := ""
+	if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
+		tokenString = authHeader[7:]
+	} else {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+		c.Abort()
+		return false
+	}
+
+	// Validate token
+	claims, err := authService.ValidateToken(tokenString)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Abort()
+		return false
+	}
+
+	// Add user info to context
+	c.Set("user_id", (*claims)["user_id"])
+	c.Set("username", (*claims)["username"])
+	c.Set("role", (*claims)["role"])
+	return true
+}
+
+// isAdmin checks the authenticated user's role, or aborts the request
+func isAdmin(c *gin.Context) bool {
+	role, exists := c.Get("role")
+	if !exists || role != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		c.Abort()
+		return false
+	}
+	return true
+}
@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"uptime-monitor/internal/auth"
 	"uptime-monitor/internal/models"
 	"uptime-monitor/internal/monitoring"
@@ -19,12 +23,18 @@ func SetupRoutes(router *gin.RouterGroup, db *sqlx.DB, monitorManager *monitorin
 	// Notification routes
 	SetupNotificationRoutes(router, db)
 
+	// Heartbeat routes for push monitors
+	SetupPushRoutes(router, db, monitorManager)
+
 	// Monitor routes
 	router.GET("/monitors", getMonitors(db))
-	router.POST("/monitors", createMonitor(db, monitorManager))
+	router.POST("/monitors", createMonitor(db, monitorManager, wsHub, authService))
 	router.GET("/monitors/:id", getMonitor(db))
-	router.PUT("/monitors/:id", updateMonitor(db, monitorManager))
-	router.DELETE("/monitors/:id", deleteMonitor(db, monitorManager))
+	router.GET("/monitor-types", getMonitorTypes(monitorManager))
+	router.PUT("/monitors/:id", updateMonitor(db, monitorManager, wsHub, authService))
+	router.DELETE("/monitors/:id", deleteMonitor(db, monitorManager, wsHub))
+	router.POST("/monitors/:id/check", checkMonitorNow(db, monitorManager, authService))
+	router.POST("/monitors/test", testMonitor(monitorManager, authService))
 
 	// Check routes
 	router.GET("/monitors/:id/checks", getMonitorChecks(db))
@@ -32,9 +42,10 @@ func SetupRoutes(router *gin.RouterGroup, db *sqlx.DB, monitorManager *monitorin
 
 	// Dashboard routes
 	router.GET("/dashboard", getDashboard(db))
+	router.GET("/scheduler", getSchedulerStats(monitorManager))
 
 	// WebSocket endpoint
-	router.GET("/ws", gin.WrapH(wsHub.HandleWebSocket()))
+	router.GET("/ws", gin.WrapH(wsHub.HandleWebSocket(authService)))
 }
 
 func getMonitors(db *sqlx.DB) gin.HandlerFunc {
@@ -63,11 +74,14 @@ func getMonitors(db *sqlx.DB) gin.HandlerFunc {
 			}
 		}
 
+		for i := range monitors {
+			monitors[i] = monitors[i].WithoutSecrets()
+		}
 		c.JSON(http.StatusOK, monitors)
 	}
 }
 
-func createMonitor(db *sqlx.DB, manager *monitoring.Manager) gin.HandlerFunc {
+func createMonitor(db *sqlx.DB, manager *monitoring.Manager, hub *websocket.Hub, authService *auth.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var monitor models.Monitor
 		if err := c.ShouldBindJSON(&monitor); err != nil {
@@ -86,20 +100,18 @@ func createMonitor(db *sqlx.DB, manager *monitoring.Manager) gin.HandlerFunc {
 			monitor.MaxRetries = 3
 		}
 
-		query := `
-			INSERT INTO monitors (name, url, type, interval, timeout, max_retries, active)
-			VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id
-		`
+		if !allowExec(c, manager, authService, monitor.Type) {
+			return
+		}
+		if err := prepareMonitor(&monitor); err != nil {
+			respondInvalidMonitor(c, err)
+			return
+		}
 
-		err := db.QueryRow(query, monitor.Name, monitor.URL, monitor.Type,
-			monitor.Interval, monitor.Timeout, monitor.MaxRetries, monitor.Active).Scan(&monitor.ID)
+		err := db.QueryRow(insertMonitorQuery+" RETURNING id", monitorValues(monitor)...).Scan(&monitor.ID)
 		if err != nil {
 			// Fallback for SQLite
-			result, err := db.Exec(`
-				INSERT INTO monitors (name, url, type, interval, timeout, max_retries, active)
-				VALUES (?, ?, ?, ?, ?, ?, ?)
-			`, monitor.Name, monitor.URL, monitor.Type,
-				monitor.Interval, monitor.Timeout, monitor.MaxRetries, monitor.Active)
+			result, err := db.Exec(insertMonitorQuery, monitorValues(monitor)...)
 
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -115,7 +127,8 @@ func createMonitor(db *sqlx.DB, manager *monitoring.Manager) gin.HandlerFunc {
 			manager.AddMonitor(monitor)
 		}
 
-		c.JSON(http.StatusCreated, monitor)
+		hub.Publish(websocket.Event{Type: websocket.EventMonitorCreated, MonitorID: monitor.ID, Data: monitor.WithoutSecrets(), Tags: monitor.TagList()})
+		c.JSON(http.StatusCreated, monitor.WithoutSecrets())
 	}
 }
 
@@ -134,11 +147,11 @@ func getMonitor(db *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
-		c.JSON(http.StatusOK, monitor)
+		c.JSON(http.StatusOK, monitor.WithoutSecrets())
 	}
 }
 
-func updateMonitor(db *sqlx.DB, manager *monitoring.Manager) gin.HandlerFunc {
+func updateMonitor(db *sqlx.DB, manager *monitoring.Manager, hub *websocket.Hub, authService *auth.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := strconv.Atoi(c.Param("id"))
 		if err != nil {
@@ -152,19 +165,59 @@ func updateMonitor(db *sqlx.DB, manager *monitoring.Manager) gin.HandlerFunc {
 			return
 		}
 
+		var existing models.Monitor
+		if err := db.Get(&existing, "SELECT * FROM monitors WHERE id = ?", id); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Monitor not found"})
+			return
+		}
+
+		// Keep the push URL stable unless a new token is supplied
 		monitor.ID = id
-		query := `
-			UPDATE monitors 
-			SET name = ?, url = ?, type = ?, interval = ?, timeout = ?, max_retries = ?, active = ?
-			WHERE id = ?
-		`
+		if monitor.PushToken == "" {
+			monitor.PushToken = existing.PushToken
+		}
+		// Secrets are not returned to clients, so a monitor sent back as it
+		// was received keeps the stored key, credentials, URL passwords and
+		// header values; the *_clear flags remove a password or token
+		if monitor.TLSClientKey == "" && monitor.TLSClientCert != "" {
+			monitor.TLSClientKey = existing.TLSClientKey
+		}
+		if monitor.AuthPassword == "" && !monitor.AuthPasswordClear {
+			monitor.AuthPassword = existing.AuthPassword
+		}
+		if monitor.AuthToken == "" && !monitor.AuthTokenClear {
+			monitor.AuthToken = existing.AuthToken
+		}
+		monitor.URL = restoreURLPassword(monitor.URL, existing.URL)
+		monitor.ProxyURL = restoreURLPassword(monitor.ProxyURL, existing.ProxyURL)
+		monitor.Headers.RestoreSecrets(existing.Headers)
+		for i := range monitor.TransactionSteps {
+			if i < len(existing.TransactionSteps) {
+				monitor.TransactionSteps[i].Headers.RestoreSecrets(existing.TransactionSteps[i].Headers)
+			}
+		}
+		if !allowExec(c, manager, authService, monitor.Type, existing.Type) {
+			return
+		}
+		if err := prepareMonitor(&monitor); err != nil {
+			respondInvalidMonitor(c, err)
+			return
+		}
 
-		_, err = db.Exec(query, monitor.Name, monitor.URL, monitor.Type,
-			monitor.Interval, monitor.Timeout, monitor.MaxRetries, monitor.Active, id)
+		_, err = db.Exec(updateMonitorQuery, append(monitorValues(monitor), id)...)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
+		// A baseline taken from other content or other filters would report
+		// a change on the next check
+		if contentSettingsChanged(existing, monitor) {
+			_, err = db.Exec("UPDATE monitors SET content_hash = '', content_snapshot = '' WHERE id = ?", id)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
 
 		// Update monitoring manager
 		manager.RemoveMonitor(id)
@@ -172,11 +225,48 @@ func updateMonitor(db *sqlx.DB, manager *monitoring.Manager) gin.HandlerFunc {
 			manager.AddMonitor(monitor)
 		}
 
-		c.JSON(http.StatusOK, monitor)
+		hub.Publish(websocket.Event{
+			Type:      websocket.EventMonitorUpdated,
+			MonitorID: id,
+			Data:      monitor.WithoutSecrets(),
+			// Old tags too, so clients subscribed to a removed tag hear about it
+			Tags: append(monitor.TagList(), existing.TagList()...),
+		})
+		c.JSON(http.StatusOK, monitor.WithoutSecrets())
 	}
 }
 
-func deleteMonitor(db *sqlx.DB, manager *monitoring.Manager) gin.HandlerFunc {
+// contentSettingsChanged reports whether the settings that decide what a
+// content mode check compares have changed
+func contentSettingsChanged(old, updated models.Monitor) bool {
+	return old.URL != updated.URL ||
+		!sameStrings(old.ContentIgnoreSelectors, updated.ContentIgnoreSelectors) ||
+		!sameStrings(old.ContentIgnoreRegex, updated.ContentIgnoreRegex)
+}
+
+// sameStrings compares lists, treating nil and empty as equal
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// restoreURLPassword returns the stored URL when raw is that URL with its
+// passwords masked
+func restoreURLPassword(raw, stored string) string {
+	if stored != "" && raw != stored && raw == models.RedactURL(stored) {
+		return stored
+	}
+	return raw
+}
+
+func deleteMonitor(db *sqlx.DB, manager *monitoring.Manager, hub *websocket.Hub) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := strconv.Atoi(c.Param("id"))
 		if err != nil {
@@ -184,6 +274,12 @@ func deleteMonitor(db *sqlx.DB, manager *monitoring.Manager) gin.HandlerFunc {
 			return
 		}
 
+		var existing models.Monitor
+		if err := db.Get(&existing, "SELECT * FROM monitors WHERE id = ?", id); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Monitor not found"})
+			return
+		}
+
 		_, err = db.Exec("DELETE FROM monitors WHERE id = ?", id)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -191,10 +287,104 @@ func deleteMonitor(db *sqlx.DB, manager *monitoring.Manager) gin.HandlerFunc {
 		}
 
 		manager.RemoveMonitor(id)
+		hub.Publish(websocket.Event{Type: websocket.EventMonitorDeleted, MonitorID: id, Tags: existing.TagList()})
 		c.JSON(http.StatusOK, gin.H{"message": "Monitor deleted"})
 	}
 }
 
+// allowExec lets a request create, change or run an exec monitor only when
+// exec monitors are enabled and the caller is an admin; it responds and
+// returns false otherwise. Requests for other types are always allowed.
+func allowExec(c *gin.Context, manager *monitoring.Manager, authService *auth.Service, types ...string) bool {
+	for _, t := range types {
+		if t != "exec" {
+			continue
+		}
+		if !manager.ExecEnabled() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "exec monitors are disabled on this server"})
+			return false
+		}
+		return requireAdmin(c, authService)
+	}
+	return true
+}
+
+// checkMonitorNow runs one attempt of a monitor's check outside its schedule;
+// the result is saved and broadcast like a scheduled one
+func checkMonitorNow(db *sqlx.DB, manager *monitoring.Manager, authService *auth.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid monitor ID"})
+			return
+		}
+
+		var monitor models.Monitor
+		if err := db.Get(&monitor, "SELECT * FROM monitors WHERE id = ?", id); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Monitor not found"})
+			return
+		}
+		if !allowExec(c, manager, authService, monitor.Type) {
+			return
+		}
+
+		check, err := manager.CheckNow(c.Request.Context(), monitor)
+		if err != nil {
+			respondCheckError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, check)
+	}
+}
+
+// testMonitor runs an unsaved monitor once and returns the result; nothing
+// is stored and no notifications are sent
+func testMonitor(manager *monitoring.Manager, authService *auth.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var monitor models.Monitor
+		if err := c.ShouldBindJSON(&monitor); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if monitor.Timeout == 0 {
+			monitor.Timeout = 30
+		}
+		if !allowExec(c, manager, authService, monitor.Type) {
+			return
+		}
+		if monitor.Type == "push" {
+			respondCheckError(c, monitoring.ErrPushMonitor)
+			return
+		}
+		if err := prepareMonitor(&monitor); err != nil {
+			respondInvalidMonitor(c, err)
+			return
+		}
+
+		check, err := manager.TestMonitor(c.Request.Context(), monitor)
+		if err != nil {
+			respondCheckError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, check)
+	}
+}
+
+// respondCheckError reports why a manual or test check did not run
+func respondCheckError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, monitoring.ErrPushMonitor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, monitoring.ErrCheckRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, monitoring.ErrStopping), errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 func getMonitorChecks(db *sqlx.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := strconv.Atoi(c.Param("id"))
@@ -236,11 +426,17 @@ func getMonitorStats(db *sqlx.DB) gin.HandlerFunc {
 				monitor_id,
 				COUNT(*) as total_checks,
 				SUM(CASE WHEN status = 'up' THEN 1 ELSE 0 END) as success_checks,
-				SUM(CASE WHEN status = 'down' THEN 1 ELSE 0 END) as failed_checks,
+				SUM(CASE WHEN status IN ('down', 'timeout') THEN 1 ELSE 0 END) as failed_checks,
+				SUM(CASE WHEN status = 'timeout' THEN 1 ELSE 0 END) as timeout_checks,
 				AVG(CASE WHEN response_time > 0 THEN response_time END) as avg_response_time,
+				AVG(dns_time) as avg_dns_time,
+				AVG(connect_time) as avg_connect_time,
+				AVG(tls_time) as avg_tls_time,
+				AVG(ttfb) as avg_ttfb,
+				AVG(transfer_time) as avg_transfer_time,
 				(SUM(CASE WHEN status = 'up' THEN 1 ELSE 0 END) * 100.0 / COUNT(*)) as uptime_percent
 			FROM monitor_checks 
-			WHERE monitor_id = ? AND checked_at > datetime('now', '-24 hours')
+			WHERE monitor_id = ? AND status != 'pending' AND checked_at > datetime('now', '-24 hours')
 		`
 
 		err = db.Get(&stats, query, id)
@@ -278,7 +474,7 @@ func getDashboard(db *sqlx.DB) gin.HandlerFunc {
 					status,
 					ROW_NUMBER() OVER (PARTITION BY monitor_id ORDER BY checked_at DESC) as rn
 				FROM monitor_checks
-				WHERE checked_at > datetime('now', '-30 minutes')
+				WHERE status != 'pending' AND checked_at > datetime('now', '-30 minutes')
 			) latest ON m.id = latest.monitor_id AND latest.rn = 1
 			WHERE m.active = ?
 		`
@@ -311,7 +507,7 @@ func getDashboard(db *sqlx.DB) gin.HandlerFunc {
 					monitor_id,
 					(SUM(CASE WHEN status = 'up' THEN 1 ELSE 0 END) * 100.0 / COUNT(*)) as uptime_percent
 				FROM monitor_checks 
-				WHERE checked_at > datetime('now', '-24 hours')
+				WHERE status != 'pending' AND checked_at > datetime('now', '-24 hours')
 				GROUP BY monitor_id
 			)
 		`
@@ -321,3 +517,176 @@ func getDashboard(db *sqlx.DB) gin.HandlerFunc {
 		c.JSON(http.StatusOK, dashboard)
 	}
 }
+
+// getMonitorTypes describes the monitor types this server can check and
+// their settings; exec is left out when exec monitors are disabled
+func getMonitorTypes(manager *monitoring.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		types := monitoring.MonitorTypes()
+		if !manager.ExecEnabled() {
+			available := types[:0]
+			for _, t := range types {
+				if t.Name != "exec" {
+					available = append(available, t)
+				}
+			}
+			types = available
+		}
+		c.JSON(http.StatusOK, types)
+	}
+}
+
+// getSchedulerStats reports the check worker pool's load and counters
+func getSchedulerStats(manager *monitoring.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, manager.SchedulerStats())
+	}
+}
+
+// monitorColumns are the user-editable monitor columns, in the order
+// returned by monitorValues
+var monitorColumns = []string{
+	"name", "url", "type", "interval", "timeout", "max_retries", "retry_interval", "active", "tags",
+	"keyword", "keyword_regex", "keyword_invert", "max_body_bytes",
+	"http_mode", "json_assertions",
+	"method", "headers", "body", "auth_method", "auth_username", "auth_password", "auth_token",
+	"accepted_status_codes", "follow_redirects", "max_redirects",
+	"dns_resolver", "dns_record_type", "dns_expected",
+	"push_token", "push_grace_period",
+	"tcp_send", "tls_mode",
+	"ping_count", "ping_max_loss", "ping_max_rtt", "ping_max_jitter", "ping_privileged", "address_family",
+	"db_query", "grpc_service",
+	"exec_args", "smtp_from", "smtp_to",
+	"transaction_steps",
+	"content_ignore_regex", "content_ignore_selectors",
+	"proxy_url", "tls_client_cert", "tls_client_key", "tls_ca_bundle", "tls_ignore_errors",
+}
+
+var (
+	insertMonitorQuery = fmt.Sprintf("INSERT INTO monitors (%s) VALUES (%s)",
+		strings.Join(monitorColumns, ", "),
+		strings.TrimSuffix(strings.Repeat("?, ", len(monitorColumns)), ", "))
+	updateMonitorQuery = fmt.Sprintf("UPDATE monitors SET %s = ? WHERE id = ?",
+		strings.Join(monitorColumns, " = ?, "))
+)
+
+func monitorValues(m models.Monitor) []interface{} {
+	return []interface{}{
+		m.Name, m.URL, m.Type, m.Interval, m.Timeout, m.MaxRetries, m.RetryInterval, m.Active, m.Tags,
+		m.Keyword, m.KeywordRegex, m.KeywordInvert, m.MaxBodyBytes,
+		m.HTTPMode, m.JSONAssertions,
+		m.Method, m.Headers, m.Body, m.AuthMethod, m.AuthUsername, m.AuthPassword, m.AuthToken,
+		m.AcceptedStatusCodes, m.FollowRedirects, m.MaxRedirects,
+		m.DNSResolver, m.DNSRecordType, m.DNSExpected,
+		m.PushToken, m.PushGracePeriod,
+		m.TCPSend, m.TLSMode,
+		m.PingCount, m.PingMaxLoss, m.PingMaxRTT, m.PingMaxJitter, m.PingPrivileged, m.AddressFamily,
+		m.DBQuery, m.GRPCService,
+		m.ExecArgs, m.SMTPFrom, m.SMTPTo,
+		m.TransactionSteps,
+		m.ContentIgnoreRegex, m.ContentIgnoreSelectors,
+		m.ProxyURL, m.TLSClientCert, m.TLSClientKey, m.TLSCABundle, m.TLSIgnoreErrors,
+	}
+}
+
+// prepareMonitor normalizes a monitor from a request, fills in defaults for
+// optional settings and validates it against its type
+func prepareMonitor(monitor *models.Monitor) error {
+	monitor.Tags = strings.Join(monitor.TagList(), ",")
+	if monitor.RetryInterval == 0 {
+		monitor.RetryInterval = 20
+	}
+
+	prepareHTTPRequest(monitor)
+	monitor.ProxyURL = strings.TrimSpace(monitor.ProxyURL)
+	prepareTransaction(monitor)
+
+	monitor.DNSRecordType = strings.ToUpper(strings.TrimSpace(monitor.DNSRecordType))
+	if monitor.DNSRecordType == "" {
+		monitor.DNSRecordType = "A"
+	}
+
+	monitor.SMTPFrom = strings.TrimSpace(monitor.SMTPFrom)
+	monitor.SMTPTo = strings.TrimSpace(monitor.SMTPTo)
+
+	if monitor.PingCount == 0 {
+		monitor.PingCount = 3
+	}
+
+	monitor.DBQuery = strings.TrimSpace(monitor.DBQuery)
+	if monitor.DBQuery == "" {
+		switch monitor.Type {
+		case "postgres", "mysql":
+			monitor.DBQuery = "SELECT 1"
+		case "redis":
+			monitor.DBQuery = "PING"
+		}
+	}
+
+	if monitor.Type == "push" && monitor.PushToken == "" {
+		token, err := monitoring.GeneratePushToken()
+		if err != nil {
+			return err
+		}
+		monitor.PushToken = token
+	}
+
+	return monitoring.ValidateMonitor(*monitor)
+}
+
+// respondInvalidMonitor reports a monitor rejected by prepareMonitor,
+// listing the failing fields when the monitor type named them
+func respondInvalidMonitor(c *gin.Context, err error) {
+	var fields monitoring.FieldErrors
+	if errors.As(err, &fields) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "fields": fields})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// prepareHTTPRequest defaults the HTTP request settings
+func prepareHTTPRequest(monitor *models.Monitor) {
+	monitor.Method = strings.ToUpper(strings.TrimSpace(monitor.Method))
+	if monitor.Method == "" {
+		monitor.Method = http.MethodGet
+	}
+
+	monitor.AcceptedStatusCodes = strings.ReplaceAll(monitor.AcceptedStatusCodes, " ", "")
+	if monitor.AcceptedStatusCodes == "" {
+		monitor.AcceptedStatusCodes = "200-399"
+	}
+
+	if monitor.FollowRedirects == nil {
+		follow := true
+		monitor.FollowRedirects = &follow
+	}
+	if monitor.MaxRedirects == 0 {
+		monitor.MaxRedirects = 10
+	}
+}
+
+// prepareTransaction defaults the steps of a transaction monitor; the
+// monitor URL defaults to the first step's
+func prepareTransaction(monitor *models.Monitor) {
+	if monitor.Type != "transaction" {
+		return
+	}
+
+	for i := range monitor.TransactionSteps {
+		step := &monitor.TransactionSteps[i]
+		step.URL = strings.TrimSpace(step.URL)
+		step.Method = strings.ToUpper(strings.TrimSpace(step.Method))
+		if step.Method == "" {
+			step.Method = http.MethodGet
+		}
+		step.AcceptedStatusCodes = strings.ReplaceAll(step.AcceptedStatusCodes, " ", "")
+		if step.AcceptedStatusCodes == "" {
+			step.AcceptedStatusCodes = "200-399"
+		}
+	}
+
+	if monitor.URL == "" && len(monitor.TransactionSteps) > 0 {
+		monitor.URL = monitor.TransactionSteps[0].URL
+	}
+}
@@ -64,6 +64,7 @@ func getAvailableEvents() gin.HandlerFunc {
 			{"id": "recovery", "name": "Recovery", "description": "When a monitor recovers from down state"},
 			{"id": "response_slow", "name": "Slow Response", "description": "When response time exceeds threshold"},
 			{"id": "ssl_expiring", "name": "SSL Expiring", "description": "When SSL certificate is about to expire"},
+			{"id": "content_changed", "name": "Content Changed", "description": "When a content mode monitor sees different content"},
 		}
 		c.JSON(http.StatusOK, events)
 	}
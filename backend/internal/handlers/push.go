@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+	"uptime-monitor/internal/models"
+	"uptime-monitor/internal/monitoring"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// PushRequest is the optional payload of a heartbeat, sent as query
+// parameters, a form or JSON
+type PushRequest struct {
+	Status   string `form:"status" json:"status"`     // up (default) or down
+	Message  string `form:"msg" json:"msg"`           // shown in the check history
+	Duration int    `form:"duration" json:"duration"` // job runtime in milliseconds
+}
+
+func SetupPushRoutes(router *gin.RouterGroup, db *sqlx.DB, manager *monitoring.Manager) {
+	// Heartbeat endpoints are authenticated by their token
+	router.GET("/push/:token", receivePush(db, manager))
+	router.POST("/push/:token", receivePush(db, manager))
+}
+
+func receivePush(db *sqlx.DB, manager *monitoring.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Param("token")
+
+		var monitor models.Monitor
+		err := db.Get(&monitor, "SELECT * FROM monitors WHERE type = ? AND push_token = ?", "push", token)
+		if err != nil || token == "" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Push monitor not found"})
+			return
+		}
+
+		var req PushRequest
+		if err := c.ShouldBind(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if req.Status == "" {
+			req.Status = "up"
+		}
+		if req.Status != "up" && req.Status != "down" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be up or down"})
+			return
+		}
+		if req.Message == "" {
+			req.Message = "Heartbeat received"
+		}
+
+		check := models.MonitorCheck{
+			MonitorID:    monitor.ID,
+			Status:       req.Status,
+			ResponseTime: req.Duration,
+			Message:      req.Message,
+			CheckedAt:    time.Now(),
+		}
+
+		if err := manager.RecordPush(monitor.ID, check); err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
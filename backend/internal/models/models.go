@@ -1,6 +1,11 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 )
 
@@ -8,25 +13,468 @@ type Monitor struct {
 	ID            int           `json:"id" db:"id"`
 	Name          string        `json:"name" db:"name"`
 	URL           string        `json:"url" db:"url"`
-	Type          string        `json:"type" db:"type"` // http, tcp, ping
+	Type          string        `json:"type" db:"type"` // http, tcp, ping, dns, push, postgres, mysql, redis, grpc, exec, smtp, imap, pop3, transaction
 	Interval      int           `json:"interval" db:"interval"`
 	Timeout       int           `json:"timeout" db:"timeout"`
 	MaxRetries    int           `json:"max_retries" db:"max_retries"`
+	RetryInterval int           `json:"retry_interval" db:"retry_interval"` // seconds between confirmation re-checks
 	Active        bool          `json:"active" db:"active"`
+	Tags          string        `json:"tags" db:"tags"` // comma-separated
 	CreatedAt     time.Time     `json:"created_at" db:"created_at"`
 	UpdatedAt     time.Time     `json:"updated_at" db:"updated_at"`
 	LastCheck     *MonitorCheck `json:"last_check,omitempty" db:"-"`
 	CurrentStatus string        `json:"current_status,omitempty" db:"-"`
+
+	// Response assertion for HTTP bodies and TCP responses
+	Keyword       string `json:"keyword" db:"keyword"`
+	KeywordRegex  bool   `json:"keyword_regex" db:"keyword_regex"`   // treat keyword as a regular expression
+	KeywordInvert bool   `json:"keyword_invert" db:"keyword_invert"` // fail when the keyword is present
+	MaxBodyBytes  int    `json:"max_body_bytes" db:"max_body_bytes"` // 0 uses the default limit
+
+	// HTTP mode and its settings
+	HTTPMode       string         `json:"http_mode" db:"http_mode"` // "" (status), json or content
+	JSONAssertions JSONAssertions `json:"json_assertions" db:"json_assertions"`
+
+	// Content mode settings; ignored regions are removed before hashing,
+	// selectors first (tag, #id, .class or combinations like div.ad)
+	ContentIgnoreRegex     StringList `json:"content_ignore_regex" db:"content_ignore_regex"`
+	ContentIgnoreSelectors StringList `json:"content_ignore_selectors" db:"content_ignore_selectors"`
+	ContentHash            string     `json:"content_hash" db:"content_hash"` // set by the checker
+	ContentSnapshot        string     `json:"-" db:"content_snapshot"`        // content behind ContentHash
+
+	// HTTP request settings
+	Method              string    `json:"method" db:"method"`
+	Headers             StringMap `json:"headers" db:"headers"`
+	Body                string    `json:"body" db:"body"`
+	AuthMethod          string    `json:"auth_method" db:"auth_method"` // "", basic or bearer
+	AuthUsername        string    `json:"auth_username" db:"auth_username"`
+	AuthPassword        string    `json:"auth_password,omitempty" db:"auth_password"`
+	AuthPasswordSet     bool      `json:"auth_password_set" db:"-"`
+	AuthPasswordClear   bool      `json:"auth_password_clear,omitempty" db:"-"` // on update, remove the stored password
+	AuthToken           string    `json:"auth_token,omitempty" db:"auth_token"`
+	AuthTokenSet        bool      `json:"auth_token_set" db:"-"`
+	AuthTokenClear      bool      `json:"auth_token_clear,omitempty" db:"-"`                // on update, remove the stored token
+	AcceptedStatusCodes string    `json:"accepted_status_codes" db:"accepted_status_codes"` // e.g. "200-299,401"
+	FollowRedirects     *bool     `json:"follow_redirects" db:"follow_redirects"`           // nil means true
+	MaxRedirects        int       `json:"max_redirects" db:"max_redirects"`
+
+	// Outbound proxy and TLS settings. The proxy applies to HTTP checks; the
+	// TLS settings to every TLS connection the monitor makes. The client key
+	// and the proxy password are never returned by the API.
+	ProxyURL        string `json:"proxy_url" db:"proxy_url"`             // http, https or socks5; "direct" skips the default
+	TLSClientCert   string `json:"tls_client_cert" db:"tls_client_cert"` // PEM
+	TLSClientKey    string `json:"tls_client_key,omitempty" db:"tls_client_key"`
+	TLSClientKeySet bool   `json:"tls_client_key_set" db:"-"`
+	TLSCABundle     string `json:"tls_ca_bundle" db:"tls_ca_bundle"` // PEM roots used instead of the system ones
+	TLSIgnoreErrors bool   `json:"tls_ignore_errors" db:"tls_ignore_errors"`
+
+	// DNS settings; the URL holds the name to resolve
+	DNSResolver   string     `json:"dns_resolver" db:"dns_resolver"` // host[:port], empty uses the system resolver
+	DNSRecordType string     `json:"dns_record_type" db:"dns_record_type"`
+	DNSExpected   StringList `json:"dns_expected" db:"dns_expected"` // expected answer set, any order
+
+	// Push (heartbeat) settings
+	PushToken       string     `json:"push_token" db:"push_token"`
+	PushGracePeriod int        `json:"push_grace_period" db:"push_grace_period"` // seconds allowed past the interval
+	LastPushAt      *time.Time `json:"last_push_at,omitempty" db:"last_push_at"`
+
+	// TCP settings; the response is checked with the keyword settings
+	TCPSend string `json:"tcp_send" db:"tcp_send"` // payload written after connecting
+	TLSMode string `json:"tls_mode" db:"tls_mode"` // "", tls, starttls-smtp, starttls-imap or starttls-pop3
+
+	// Ping settings; zero thresholds are not enforced
+	PingCount      int      `json:"ping_count" db:"ping_count"`
+	PingMaxLoss    *float64 `json:"ping_max_loss" db:"ping_max_loss"`     // percent; nil only requires one reply
+	PingMaxRTT     int      `json:"ping_max_rtt" db:"ping_max_rtt"`       // milliseconds, compared to the average
+	PingMaxJitter  int      `json:"ping_max_jitter" db:"ping_max_jitter"` // milliseconds, compared to the RTT stddev
+	PingPrivileged bool     `json:"ping_privileged" db:"ping_privileged"` // raw ICMP instead of UDP pings
+	AddressFamily  string   `json:"address_family" db:"address_family"`   // "", ipv4, ipv6 or both (http, tcp and ping)
+
+	// Database settings; the URL holds the connection string, credentials
+	// come from auth_username and auth_password, and json_assertions are
+	// evaluated against the first row ("value" is the first column)
+	DBQuery string `json:"db_query" db:"db_query"` // SQL query or Redis command
+
+	// gRPC settings; tls_mode "tls" or a grpcs:// URL enables TLS
+	GRPCService string `json:"grpc_service" db:"grpc_service"` // empty checks the whole server
+
+	// Exec settings; the URL holds the absolute path of the command, which
+	// runs without a shell
+	ExecArgs StringList `json:"exec_args" db:"exec_args"`
+
+	// Mail settings; auth_username and auth_password log in over TLS, and
+	// SMTP monitors probe the envelope without DATA when these are set
+	SMTPFrom string `json:"smtp_from" db:"smtp_from"` // empty sends the null sender
+	SMTPTo   string `json:"smtp_to" db:"smtp_to"`
+
+	// Transaction steps run in order with a shared cookie jar; the HTTP
+	// authentication and redirect settings apply to every step
+	TransactionSteps TransactionSteps `json:"transaction_steps" db:"transaction_steps"`
+}
+
+// Authentication methods for HTTP requests
+const (
+	AuthNone   = ""
+	AuthBasic  = "basic"
+	AuthBearer = "bearer"
+)
+
+// TLS modes for TCP connections
+const (
+	TLSModeNone         = ""
+	TLSModeTLS          = "tls"
+	TLSModeStartTLS     = "starttls" // the mail monitor's own protocol
+	TLSModeStartTLSSMTP = "starttls-smtp"
+	TLSModeStartTLSIMAP = "starttls-imap"
+	TLSModeStartTLSPOP3 = "starttls-pop3"
+)
+
+// Address families a monitor can be restricted to
+const (
+	AddressFamilyAny  = ""
+	AddressFamilyIPv4 = "ipv4"
+	AddressFamilyIPv6 = "ipv6"
+	AddressFamilyBoth = "both" // IPv6 and IPv4 must each pass
+)
+
+// HTTP monitor modes
+const (
+	HTTPModeStatus  = ""
+	HTTPModeJSON    = "json"
+	HTTPModeContent = "content"
+)
+
+// JSONAssertion checks a value in a JSON response, e.g. {"path": "db",
+// "operator": "==", "expected": "ok"}. Paths use gjson-style dots
+// (items.0.name, items.#) or JSONPath ($.items[0].name).
+type JSONAssertion struct {
+	Path     string `json:"path"`
+	Operator string `json:"operator"` // ==, !=, <, >, contains, exists
+	Expected string `json:"expected"`
+}
+
+// JSONAssertions is stored as a JSON array in a TEXT column
+type JSONAssertions []JSONAssertion
+
+func (a JSONAssertions) Value() (driver.Value, error) {
+	if len(a) == 0 {
+		return "[]", nil
+	}
+	b, err := json.Marshal(a)
+	return string(b), err
+}
+
+func (a *JSONAssertions) Scan(src interface{}) error {
+	return scanJSON(src, a)
+}
+
+// TransactionStep is one request of a transaction monitor. Variables
+// captured by earlier steps replace {{name}} in the URL, headers and body.
+type TransactionStep struct {
+	Name                string         `json:"name"`
+	Method              string         `json:"method"`
+	URL                 string         `json:"url"`
+	Headers             StringMap      `json:"headers,omitempty"`
+	Body                string         `json:"body,omitempty"`
+	AcceptedStatusCodes string         `json:"accepted_status_codes,omitempty"` // default 200-399
+	Keyword             string         `json:"keyword,omitempty"`
+	KeywordRegex        bool           `json:"keyword_regex,omitempty"`
+	KeywordInvert       bool           `json:"keyword_invert,omitempty"`
+	JSONAssertions      JSONAssertions `json:"json_assertions,omitempty"`
+	Captures            []StepCapture  `json:"captures,omitempty"`
+}
+
+// StepCapture stores part of a step's response in a variable, taken either
+// by regex (the first group, or the whole match) or by JSON path
+type StepCapture struct {
+	Name  string `json:"name"`
+	Regex string `json:"regex,omitempty"`
+	Path  string `json:"path,omitempty"`
+}
+
+// TransactionSteps is stored as a JSON array in a TEXT column
+type TransactionSteps []TransactionStep
+
+func (t TransactionSteps) Value() (driver.Value, error) {
+	if len(t) == 0 {
+		return "[]", nil
+	}
+	b, err := json.Marshal(t)
+	return string(b), err
+}
+
+func (t *TransactionSteps) Scan(src interface{}) error {
+	return scanJSON(src, t)
+}
+
+// Metric is a performance value reported by a check, such as one item of
+// Nagios perfdata ("time=0.12s;1;2;0;10")
+type Metric struct {
+	Label string   `json:"label"`
+	Value float64  `json:"value"`
+	Unit  string   `json:"unit,omitempty"`
+	Warn  string   `json:"warn,omitempty"` // threshold range as reported
+	Crit  string   `json:"crit,omitempty"`
+	Min   *float64 `json:"min,omitempty"`
+	Max   *float64 `json:"max,omitempty"`
+}
+
+// Metrics is stored as a JSON array in a TEXT column
+type Metrics []Metric
+
+func (m Metrics) Value() (driver.Value, error) {
+	if len(m) == 0 {
+		return "[]", nil
+	}
+	b, err := json.Marshal(m)
+	return string(b), err
+}
+
+func (m *Metrics) Scan(src interface{}) error {
+	return scanJSON(src, m)
+}
+
+// CheckStep records one step of a multi-step check, such as a mail session
+// or an HTTP transaction
+type CheckStep struct {
+	Name     string  `json:"name"`
+	Duration float64 `json:"duration"` // milliseconds
+	Error    string  `json:"error,omitempty"`
+}
+
+// CheckSteps is stored as a JSON array in a TEXT column
+type CheckSteps []CheckStep
+
+func (s CheckSteps) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return "[]", nil
+	}
+	b, err := json.Marshal(s)
+	return string(b), err
+}
+
+func (s *CheckSteps) Scan(src interface{}) error {
+	return scanJSON(src, s)
+}
+
+// FamilyResult is the outcome of a check over one address family
+type FamilyResult struct {
+	Family       string `json:"family"`
+	Status       string `json:"status"`
+	ResponseTime int    `json:"response_time"` // milliseconds
+	Message      string `json:"message"`
+}
+
+// FamilyResults is stored as a JSON array in a TEXT column
+type FamilyResults []FamilyResult
+
+func (r FamilyResults) Value() (driver.Value, error) {
+	if len(r) == 0 {
+		return "[]", nil
+	}
+	b, err := json.Marshal(r)
+	return string(b), err
+}
+
+func (r *FamilyResults) Scan(src interface{}) error {
+	return scanJSON(src, r)
+}
+
+// StringMap is stored as a JSON object in a TEXT column
+type StringMap map[string]string
+
+func (m StringMap) Value() (driver.Value, error) {
+	if len(m) == 0 {
+		return "{}", nil
+	}
+	b, err := json.Marshal(m)
+	return string(b), err
+}
+
+func (m *StringMap) Scan(src interface{}) error {
+	return scanJSON(src, m)
+}
+
+// StringList is stored as a JSON array in a TEXT column
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return "[]", nil
+	}
+	b, err := json.Marshal(l)
+	return string(b), err
+}
+
+func (l *StringList) Scan(src interface{}) error {
+	return scanJSON(src, l)
+}
+
+// scanJSON decodes a TEXT column holding JSON
+func scanJSON(src interface{}, dest interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("unsupported type %T for JSON column", src)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, dest)
+}
+
+// TagList returns the monitor's tags as a trimmed, non-empty list
+func (m Monitor) TagList() []string {
+	tags := []string{}
+	for _, tag := range strings.Split(m.Tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// SecretMask replaces the secrets that are returned masked rather than
+// dropped, so clients can tell which part of a value is hidden
+const SecretMask = "xxxxx"
+
+// WithoutSecrets returns a copy of the monitor that is safe to send to
+// clients: the client key, password and token are dropped, and the passwords
+// in the monitor and proxy URLs and sensitive header values masked
+func (m Monitor) WithoutSecrets() Monitor {
+	m.TLSClientKeySet = m.TLSClientKey != ""
+	m.TLSClientKey = ""
+	m.AuthPasswordSet = m.AuthPassword != ""
+	m.AuthPassword = ""
+	m.AuthTokenSet = m.AuthToken != ""
+	m.AuthToken = ""
+	m.URL = RedactURL(m.URL)
+	m.ProxyURL = RedactURL(m.ProxyURL)
+	m.Headers = m.Headers.redacted()
+	if m.TransactionSteps != nil {
+		steps := make(TransactionSteps, len(m.TransactionSteps))
+		for i, step := range m.TransactionSteps {
+			step.Headers = step.Headers.redacted()
+			steps[i] = step
+		}
+		m.TransactionSteps = steps
+	}
+	return m
+}
+
+// RedactURL masks the password in a URL's user info and in a password query
+// parameter, as used by database connection URLs. Other URLs are returned
+// unchanged.
+func RedactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	redacted := false
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), SecretMask)
+		redacted = true
+	}
+	if query := u.Query(); query.Get("password") != "" {
+		query.Set("password", SecretMask)
+		u.RawQuery = query.Encode()
+		redacted = true
+	}
+	if !redacted {
+		return raw
+	}
+	return u.String()
+}
+
+// SensitiveHeader reports whether a request header usually carries a
+// credential, like Authorization, Cookie or X-API-Key
+func SensitiveHeader(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range []string{"auth", "cookie", "key", "token", "secret", "password", "session", "signature"} {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// redacted returns a copy of the headers with sensitive values masked
+func (m StringMap) redacted() StringMap {
+	if m == nil {
+		return nil
+	}
+	headers := make(StringMap, len(m))
+	for name, value := range m {
+		if SensitiveHeader(name) && value != "" {
+			value = SecretMask
+		}
+		headers[name] = value
+	}
+	return headers
+}
+
+// RestoreSecrets replaces the masked sensitive values in m with the stored
+// values of the same headers
+func (m StringMap) RestoreSecrets(stored StringMap) {
+	for name, value := range m {
+		if value == SecretMask && SensitiveHeader(name) {
+			if original, ok := stored[name]; ok {
+				m[name] = original
+			}
+		}
+	}
 }
 
 type MonitorCheck struct {
 	ID           int       `json:"id" db:"id"`
 	MonitorID    int       `json:"monitor_id" db:"monitor_id"`
-	Status       string    `json:"status" db:"status"`               // up, down, unknown
+	Status       string    `json:"status" db:"status"`               // up, degraded, down, timeout, pending, unknown
 	ResponseTime int       `json:"response_time" db:"response_time"` // milliseconds
 	StatusCode   int       `json:"status_code" db:"status_code"`
 	Message      string    `json:"message" db:"message"`
 	CheckedAt    time.Time `json:"checked_at" db:"checked_at"`
+
+	// TLS certificate details, set for https checks
+	CertExpiresAt     *time.Time `json:"cert_expires_at,omitempty" db:"cert_expires_at"`
+	CertIssuer        string     `json:"cert_issuer,omitempty" db:"cert_issuer"`
+	CertSANs          string     `json:"cert_sans,omitempty" db:"cert_sans"` // comma-separated
+	CertDaysRemaining *int       `json:"cert_days_remaining,omitempty" db:"cert_days_remaining"`
+
+	// Ping statistics in milliseconds and percent, set for ping checks
+	PingMin    *float64 `json:"ping_min,omitempty" db:"ping_min"`
+	PingAvg    *float64 `json:"ping_avg,omitempty" db:"ping_avg"`
+	PingMax    *float64 `json:"ping_max,omitempty" db:"ping_max"`
+	PingStdDev *float64 `json:"ping_stddev,omitempty" db:"ping_stddev"`
+	PacketLoss *float64 `json:"packet_loss,omitempty" db:"packet_loss"`
+
+	// HTTP request phases in milliseconds, set for http checks
+	DNSTime      *float64 `json:"dns_time,omitempty" db:"dns_time"`
+	ConnectTime  *float64 `json:"connect_time,omitempty" db:"connect_time"`
+	TLSTime      *float64 `json:"tls_time,omitempty" db:"tls_time"`
+	TTFB         *float64 `json:"ttfb,omitempty" db:"ttfb"` // request written to first response byte
+	TransferTime *float64 `json:"transfer_time,omitempty" db:"transfer_time"`
+
+	// Performance data, set for exec checks
+	Metrics Metrics `json:"metrics,omitempty" db:"metrics"`
+
+	// Content hash and changes since the previous check, set in content mode
+	ContentHash string `json:"content_hash,omitempty" db:"content_hash"`
+	ContentDiff string `json:"content_diff,omitempty" db:"content_diff"` // unified diff summary
+	// New baseline for the monitor, stored along with the check; nil when
+	// the content is unchanged
+	ContentSnapshot *string `json:"-" db:"-"`
+
+	// Per-step timings, set for mail and transaction checks
+	Steps CheckSteps `json:"steps,omitempty" db:"steps"`
+
+	// Per-family results, set when the address family is "both"
+	Families FamilyResults `json:"families,omitempty" db:"families"`
 }
 
 type User struct {
@@ -51,12 +499,20 @@ type Alert struct {
 }
 
 type MonitorStats struct {
-	MonitorID       int     `json:"monitor_id"`
-	UptimePercent   float64 `json:"uptime_percent"`
-	TotalChecks     int     `json:"total_checks"`
-	SuccessChecks   int     `json:"success_checks"`
-	FailedChecks    int     `json:"failed_checks"`
-	AvgResponseTime float64 `json:"avg_response_time"`
+	MonitorID       int     `json:"monitor_id" db:"monitor_id"`
+	UptimePercent   float64 `json:"uptime_percent" db:"uptime_percent"`
+	TotalChecks     int     `json:"total_checks" db:"total_checks"`
+	SuccessChecks   int     `json:"success_checks" db:"success_checks"`
+	FailedChecks    int     `json:"failed_checks" db:"failed_checks"` // down or timed out
+	TimeoutChecks   int     `json:"timeout_checks" db:"timeout_checks"`
+	AvgResponseTime float64 `json:"avg_response_time" db:"avg_response_time"`
+
+	// HTTP phase averages in milliseconds, nil without HTTP timings
+	AvgDNSTime      *float64 `json:"avg_dns_time" db:"avg_dns_time"`
+	AvgConnectTime  *float64 `json:"avg_connect_time" db:"avg_connect_time"`
+	AvgTLSTime      *float64 `json:"avg_tls_time" db:"avg_tls_time"`
+	AvgTTFB         *float64 `json:"avg_ttfb" db:"avg_ttfb"`
+	AvgTransferTime *float64 `json:"avg_transfer_time" db:"avg_transfer_time"`
 }
 
 // Notification types and structures
@@ -79,6 +535,7 @@ const (
 	EventResponseSlow    NotificationEvent = "response_slow"
 	EventSSLExpiringSoon NotificationEvent = "ssl_expiring"
 	EventRecovery        NotificationEvent = "recovery"
+	EventContentChanged  NotificationEvent = "content_changed"
 )
 
 // NotificationChannelConfig for frontend
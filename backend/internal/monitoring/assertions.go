@@ -0,0 +1,77 @@
+package monitoring
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// defaultMaxBodyBytes caps how much of a response is read for assertions
+const defaultMaxBodyBytes = 1 << 20
+
+// snippetLength is the amount of context included in failure messages
+const snippetLength = 120
+
+// readLimited reads at most the monitor's body limit from r
+func (mc *MonitorChecker) readLimited(r io.Reader) ([]byte, error) {
+	limit := mc.monitor.MaxBodyBytes
+	if limit <= 0 {
+		limit = defaultMaxBodyBytes
+	}
+	return io.ReadAll(io.LimitReader(r, int64(limit)))
+}
+
+// matchKeyword checks content against the monitor's keyword settings. It
+// returns a failure description, or "" when the assertion holds.
+func (mc *MonitorChecker) matchKeyword(content []byte) (string, error) {
+	keyword := mc.monitor.Keyword
+	text := string(content)
+
+	start, end := -1, -1
+	if mc.monitor.KeywordRegex {
+		re, err := regexp.Compile(keyword)
+		if err != nil {
+			return "", fmt.Errorf("invalid keyword regex: %v", err)
+		}
+		if loc := re.FindStringIndex(text); loc != nil {
+			start, end = loc[0], loc[1]
+		}
+	} else if i := strings.Index(text, keyword); i >= 0 {
+		start, end = i, i+len(keyword)
+	}
+
+	found := start >= 0
+	switch {
+	case found && mc.monitor.KeywordInvert:
+		return fmt.Sprintf("Keyword %q found: %q", keyword, snippet(text, start, end)), nil
+	case !found && !mc.monitor.KeywordInvert:
+		return fmt.Sprintf("Keyword %q not found in: %q", keyword, snippet(text, 0, 0)), nil
+	}
+	return "", nil
+}
+
+// snippet returns the text around [start, end) with whitespace collapsed,
+// trimmed to roughly snippetLength characters
+func snippet(text string, start, end int) string {
+	from := start - snippetLength/2
+	if from < 0 {
+		from = 0
+	}
+	to := end + snippetLength/2
+	if end == 0 || to-from < snippetLength {
+		to = from + snippetLength
+	}
+	if to > len(text) {
+		to = len(text)
+	}
+
+	s := strings.Join(strings.Fields(strings.ToValidUTF8(text[from:to], "")), " ")
+	if from > 0 {
+		s = "…" + s
+	}
+	if to < len(text) {
+		s += "…"
+	}
+	return s
+}
@@ -0,0 +1,102 @@
+package monitoring
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"uptime-monitor/internal/models"
+)
+
+// recordCertificate stores the leaf issuer and names and the earliest expiry
+// in the presented chain on the check
+func recordCertificate(check *models.MonitorCheck, chain []*x509.Certificate) {
+	if len(chain) == 0 {
+		return
+	}
+
+	leaf := chain[0]
+	expiresAt := leaf.NotAfter
+	for _, cert := range chain[1:] {
+		if cert.NotAfter.Before(expiresAt) {
+			expiresAt = cert.NotAfter
+		}
+	}
+
+	days := int(time.Until(expiresAt).Hours() / 24)
+	check.CertExpiresAt = &expiresAt
+	check.CertDaysRemaining = &days
+	check.CertIssuer = leaf.Issuer.String()
+
+	sans := append([]string{}, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	check.CertSANs = strings.Join(sans, ",")
+}
+
+// verifyCertificate checks the chain against the roots and the server name
+func verifyCertificate(cs tls.ConnectionState, roots *x509.CertPool) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("server presented no certificate")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	leaf := cs.PeerCertificates[0]
+	_, err := leaf.Verify(x509.VerifyOptions{
+		DNSName:       cs.ServerName,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	if err == nil {
+		return nil
+	}
+
+	var invalid x509.CertificateInvalidError
+	var hostname x509.HostnameError
+	switch {
+	case errors.As(err, &invalid) && invalid.Reason == x509.Expired:
+		return fmt.Errorf("certificate expired on %s", leaf.NotAfter.Format("2006-01-02"))
+	case errors.As(err, &hostname):
+		return fmt.Errorf("certificate hostname mismatch: %v", err)
+	default:
+		return fmt.Errorf("certificate verification failed: %v", err)
+	}
+}
+
+// crossesExpiryThreshold reports whether the days remaining dropped to or
+// below a notification threshold since the previous check
+func crossesExpiryThreshold(current, previous *int, thresholds []int) bool {
+	if current == nil {
+		return false
+	}
+	for _, threshold := range thresholds {
+		if *current <= threshold && (previous == nil || *previous > threshold) {
+			return true
+		}
+	}
+	return false
+}
+
+// lastCertDaysRemaining returns the days remaining recorded by the latest
+// check that saw a certificate
+func (mc *MonitorChecker) lastCertDaysRemaining() *int {
+	var days *int
+	err := mc.manager.db.Get(&days, `
+		SELECT cert_days_remaining FROM monitor_checks 
+		WHERE monitor_id = ? AND cert_days_remaining IS NOT NULL
+		ORDER BY checked_at DESC 
+		LIMIT 1
+	`, mc.monitor.ID)
+
+	if err != nil {
+		return nil
+	}
+	return days
+}
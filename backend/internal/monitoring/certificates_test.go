@@ -0,0 +1,141 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"uptime-monitor/internal/models"
+)
+
+// selfSigned returns a certificate for 127.0.0.1 valid until notAfter
+func selfSigned(t *testing.T, issuer string, notAfter time.Time) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: issuer},
+		NotBefore:    notAfter.AddDate(0, -3, 0),
+		NotAfter:     notAfter,
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:     []string{"test.example"},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func certPEM(cert *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+}
+
+func TestHTTPCheckRecordsCertificate(t *testing.T) {
+	valid := selfSigned(t, "Valid Test CA", time.Now().Add(45*24*time.Hour).Truncate(time.Second))
+	expired := selfSigned(t, "Expired Test CA", time.Now().Add(-48*time.Hour).Truncate(time.Second))
+
+	serve := func(cert tls.Certificate) *httptest.Server {
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+		server.Config.ErrorLog = log.New(io.Discard, "", 0) // rejected handshakes are expected
+		server.StartTLS()
+		t.Cleanup(server.Close)
+		return server
+	}
+	validServer, expiredServer := serve(valid), serve(expired)
+	// The address of the valid server under a name its certificate lacks
+	otherName := strings.Replace(validServer.URL, "127.0.0.1", "localhost", 1)
+
+	tests := []struct {
+		name    string
+		url     string
+		bundle  string
+		ignore  bool
+		err     string // expected failure; empty when the check passes
+		cert    tls.Certificate
+		expires int // days remaining
+	}{
+		{"trusted", validServer.URL, certPEM(valid.Leaf), false, "", valid, 44},
+		{"untrusted", validServer.URL, "", false, "certificate verification failed", valid, 44},
+		{"untrusted but ignored", validServer.URL, "", true, "", valid, 44},
+		{"hostname mismatch", otherName, certPEM(valid.Leaf), false, "certificate hostname mismatch", valid, 44},
+		{"expired", expiredServer.URL, certPEM(expired.Leaf), false, "certificate expired on " + expired.Leaf.NotAfter.Format("2006-01-02"), expired, -2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := models.Monitor{
+				Type: "http", URL: tt.url, Timeout: 5, AcceptedStatusCodes: "200",
+				TLSCABundle: tt.bundle, TLSIgnoreErrors: tt.ignore,
+			}
+			mc := &MonitorChecker{monitor: monitor, manager: &Manager{}}
+			var check models.MonitorCheck
+			err := mc.checker().Check(context.Background(), &check)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Fatalf("check failed: %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Fatalf("error %v, want %q", err, tt.err)
+			}
+
+			// The certificate is recorded whether or not it verifies
+			if check.CertExpiresAt == nil || !check.CertExpiresAt.Equal(tt.cert.Leaf.NotAfter) {
+				t.Errorf("expiry %v, want %v", check.CertExpiresAt, tt.cert.Leaf.NotAfter)
+			}
+			if check.CertDaysRemaining == nil || *check.CertDaysRemaining != tt.expires {
+				t.Errorf("days remaining %v, want %d", check.CertDaysRemaining, tt.expires)
+			}
+			if check.CertIssuer != tt.cert.Leaf.Issuer.String() {
+				t.Errorf("issuer %q, want %q", check.CertIssuer, tt.cert.Leaf.Issuer.String())
+			}
+			if check.CertSANs != "test.example,127.0.0.1" {
+				t.Errorf("names %q", check.CertSANs)
+			}
+		})
+	}
+}
+
+func TestRecordCertificateEarliestExpiry(t *testing.T) {
+	leaf := selfSigned(t, "Leaf", time.Now().Add(90*24*time.Hour)).Leaf
+	intermediate := selfSigned(t, "Intermediate", time.Now().Add(10*24*time.Hour+time.Hour)).Leaf
+
+	var check models.MonitorCheck
+	recordCertificate(&check, []*x509.Certificate{leaf, intermediate})
+	if !check.CertExpiresAt.Equal(intermediate.NotAfter) || *check.CertDaysRemaining != 10 {
+		t.Errorf("expiry %v (%d days), want the intermediate's %v", check.CertExpiresAt, *check.CertDaysRemaining, intermediate.NotAfter)
+	}
+	if check.CertIssuer != "CN=Leaf" {
+		t.Errorf("issuer %q, want the leaf's", check.CertIssuer)
+	}
+
+	var empty models.MonitorCheck
+	recordCertificate(&empty, nil)
+	if empty.CertExpiresAt != nil || empty.CertDaysRemaining != nil {
+		t.Errorf("recorded an empty chain: %+v", empty)
+	}
+}
@@ -0,0 +1,105 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"uptime-monitor/internal/models"
+)
+
+// defaultCheckTimeout applies to monitors saved without a timeout
+const defaultCheckTimeout = 30 * time.Second
+
+// Checker runs one attempt of a monitor's check and fills in check. It
+// should return once ctx is done; a returned error marks the check down.
+type Checker interface {
+	Check(ctx context.Context, check *models.MonitorCheck) error
+}
+
+// CheckerFunc adapts a function to the Checker interface
+type CheckerFunc func(ctx context.Context, check *models.MonitorCheck) error
+
+func (f CheckerFunc) Check(ctx context.Context, check *models.MonitorCheck) error {
+	return f(ctx, check)
+}
+
+// checker returns the Checker registered for the monitor's type, or nil
+// if the type is unknown or not checked on a schedule
+func (mc *MonitorChecker) checker() Checker {
+	t, ok := LookupMonitorType(mc.monitor.Type)
+	if !ok || t.NewChecker == nil {
+		return nil
+	}
+	return t.NewChecker(mc)
+}
+
+// familyChecker runs check once per address family the monitor asks for
+func (mc *MonitorChecker) familyChecker(check func(*MonitorChecker, context.Context, *models.MonitorCheck) error) Checker {
+	return CheckerFunc(func(ctx context.Context, c *models.MonitorCheck) error {
+		return mc.checkFamilies(ctx, c, check)
+	})
+}
+
+// reportMargin is added to the deadline of checks that enforce the monitor's
+// timeout themselves and still report what they saw when it expires: a
+// ping with packet loss always runs until its timeout, and an exec command
+// is killed at it
+const reportMargin = 2 * time.Second
+
+// deadline is the hard limit for one check attempt: the monitor's timeout
+// for each round of requests the check makes
+func (mc *MonitorChecker) deadline() time.Duration {
+	timeout := time.Duration(mc.monitor.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultCheckTimeout
+	}
+	if mc.monitor.Type == "ping" || mc.monitor.Type == "exec" {
+		timeout += reportMargin
+	}
+
+	switch {
+	case mc.monitor.Type == "transaction" && len(mc.monitor.TransactionSteps) > 1:
+		return timeout * time.Duration(len(mc.monitor.TransactionSteps))
+	case mc.monitor.AddressFamily == models.AddressFamilyBoth:
+		return timeout * time.Duration(len(bothFamilies))
+	}
+	return timeout
+}
+
+// runChecker runs checker with the monitor's deadline. The checker runs in
+// its own goroutine, so one that ignores its context is abandoned at the
+// deadline rather than holding the worker. Checks that reach the deadline
+// are recorded as "timeout"; ctx's own cancellation is returned as an error.
+func (mc *MonitorChecker) runChecker(ctx context.Context, checker Checker, check models.MonitorCheck) (models.MonitorCheck, error) {
+	deadline := mc.deadline()
+	checkCtx, cancel := context.WithTimeout(ctx, deadline)
+	defer cancel()
+
+	type outcome struct {
+		check models.MonitorCheck
+		err   error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result := check
+		err := checker.Check(checkCtx, &result)
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case o := <-done:
+		if checkCtx.Err() == nil {
+			return o.check, o.err
+		}
+		// Keep what the checker recorded before it gave up
+		check = o.check
+	case <-checkCtx.Done():
+	}
+
+	if ctx.Err() != nil {
+		return check, ctx.Err()
+	}
+	check.Status = "timeout"
+	check.Message = fmt.Sprintf("Check timed out after %s", deadline)
+	return check, nil
+}
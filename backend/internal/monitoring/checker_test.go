@@ -0,0 +1,43 @@
+package monitoring
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"uptime-monitor/internal/models"
+)
+
+func TestDeadline(t *testing.T) {
+	tests := []struct {
+		name    string
+		monitor models.Monitor
+		want    time.Duration
+	}{
+		{"monitor timeout", models.Monitor{Type: "http", Timeout: 5}, 5 * time.Second},
+		{"default timeout", models.Monitor{Type: "tcp"}, defaultCheckTimeout},
+		{"transaction steps", models.Monitor{Type: "transaction", Timeout: 5, TransactionSteps: make(models.TransactionSteps, 3)}, 15 * time.Second},
+		{"both families", models.Monitor{Type: "http", Timeout: 5, AddressFamily: models.AddressFamilyBoth}, 10 * time.Second},
+		{"ping reports its own timeout", models.Monitor{Type: "ping", Timeout: 5}, 5*time.Second + reportMargin},
+		{"ping over both families", models.Monitor{Type: "ping", Timeout: 5, AddressFamily: models.AddressFamilyBoth}, 2 * (5*time.Second + reportMargin)},
+		{"exec reports its own timeout", models.Monitor{Type: "exec", Timeout: 5}, 5*time.Second + reportMargin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := &MonitorChecker{monitor: tt.monitor}
+			if got := mc.deadline(); got != tt.want {
+				t.Errorf("deadline %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecTimeoutReportedByChecker(t *testing.T) {
+	monitor := models.Monitor{Type: "exec", URL: "/bin/sleep", ExecArgs: models.StringList{"10"}, Timeout: 1}
+	mc := &MonitorChecker{monitor: monitor, manager: &Manager{execEnabled: true, execPath: "/bin:/usr/bin"}}
+
+	check := mc.runCheck(context.Background())
+	if check.Status != "down" || !strings.Contains(check.Message, "command timed out after 1s") {
+		t.Errorf("got %s %q, want the command's own timeout", check.Status, check.Message)
+	}
+}
@@ -0,0 +1,195 @@
+package monitoring
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"uptime-monitor/internal/models"
+
+	"golang.org/x/net/html"
+)
+
+// compareContent hashes the body, minus its ignored regions, and compares it
+// with the content seen by the previous saved check. The first check records
+// a baseline; later changes are described in check.ContentDiff.
+func (mc *MonitorChecker) compareContent(check *models.MonitorCheck, body []byte) error {
+	content, err := normalizeContent(body, mc.monitor.ContentIgnoreSelectors, mc.monitor.ContentIgnoreRegex)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	var previous struct {
+		Hash     string `db:"content_hash"`
+		Snapshot string `db:"content_snapshot"`
+	}
+	// Test runs compare against nothing and record no baseline
+	if !mc.dryRun {
+		err = mc.manager.db.Get(&previous, "SELECT content_hash, content_snapshot FROM monitors WHERE id = ?", mc.monitor.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load previous content: %v", err)
+		}
+	}
+
+	check.Status = "up"
+	check.ContentHash = hash
+	switch {
+	case mc.dryRun:
+		check.Message = fmt.Sprintf("Content fetched (%d bytes after filtering)", len(content))
+	case previous.Hash == "":
+		check.Message = "Content baseline recorded"
+	case previous.Hash == hash:
+		check.Message = "Content unchanged"
+	default:
+		diff, added, removed := unifiedDiff(previous.Snapshot, string(content))
+		check.ContentDiff = diff
+		check.Message = fmt.Sprintf("Content changed: %d lines added, %d removed", added, removed)
+	}
+
+	// The new baseline is stored by saveCheck, so a check that is abandoned
+	// does not lose the change it saw
+	if previous.Hash != hash && !mc.dryRun {
+		snapshot := string(content)
+		check.ContentSnapshot = &snapshot
+	}
+	return nil
+}
+
+// normalizeContent removes the elements matching selectors, then the text
+// matching patterns
+func normalizeContent(body []byte, selectors, patterns []string) ([]byte, error) {
+	if len(selectors) > 0 {
+		var err error
+		if body, err = removeElements(body, selectors); err != nil {
+			return nil, err
+		}
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern %s: %v", pattern, err)
+		}
+		body = re.ReplaceAll(body, nil)
+	}
+	return body, nil
+}
+
+// removeElements parses body as HTML and renders it again without the
+// elements matching any of the selectors
+func removeElements(body []byte, selectors []string) ([]byte, error) {
+	parsed := make([]contentSelector, 0, len(selectors))
+	for _, s := range selectors {
+		sel, err := parseContentSelector(s)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, sel)
+	}
+
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %v", err)
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; {
+			next := child.NextSibling
+			if child.Type == html.ElementNode && matchesAny(child, parsed) {
+				n.RemoveChild(child)
+			} else {
+				walk(child)
+			}
+			child = next
+		}
+	}
+	walk(doc)
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// contentSelector is a compound selector: an optional tag, id and classes
+type contentSelector struct {
+	tag     string
+	id      string
+	classes []string
+}
+
+// selectorPart matches one tag, #id or .class in a compound selector
+var selectorPart = regexp.MustCompile(`^([#.]?)([A-Za-z0-9_-]+)`)
+
+// ValidateContentSelector checks an ignore selector such as div, #ad or
+// span.timestamp
+func ValidateContentSelector(s string) error {
+	_, err := parseContentSelector(s)
+	return err
+}
+
+func parseContentSelector(s string) (contentSelector, error) {
+	var sel contentSelector
+	rest := strings.TrimSpace(s)
+	if rest == "" {
+		return sel, fmt.Errorf("empty selector")
+	}
+	for first := true; rest != ""; first = false {
+		match := selectorPart.FindStringSubmatch(rest)
+		if match == nil {
+			return sel, fmt.Errorf("unsupported selector %q: use tag, #id, .class or a combination like div.ad", s)
+		}
+		switch match[1] {
+		case "":
+			if !first {
+				return sel, fmt.Errorf("unsupported selector %q: the tag must come first", s)
+			}
+			sel.tag = strings.ToLower(match[2])
+		case "#":
+			sel.id = match[2]
+		case ".":
+			sel.classes = append(sel.classes, match[2])
+		}
+		rest = rest[len(match[0]):]
+	}
+	return sel, nil
+}
+
+func matchesAny(n *html.Node, selectors []contentSelector) bool {
+	for _, sel := range selectors {
+		if sel.matches(n) {
+			return true
+		}
+	}
+	return false
+}
+
+func (sel contentSelector) matches(n *html.Node) bool {
+	if sel.tag != "" && n.Data != sel.tag {
+		return false
+	}
+	var id string
+	var classes []string
+	for _, attr := range n.Attr {
+		switch attr.Key {
+		case "id":
+			id = attr.Val
+		case "class":
+			classes = strings.Fields(attr.Val)
+		}
+	}
+	if sel.id != "" && id != sel.id {
+		return false
+	}
+	for _, class := range sel.classes {
+		if !containsString(classes, class) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"uptime-monitor/internal/config"
+	"uptime-monitor/internal/models"
+)
+
+func TestContentBaselineSavedWithCheck(t *testing.T) {
+	page := "<p>one</p>"
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, page)
+	}))
+	defer target.Close()
+
+	m, db := newTestManager(t, config.MonitorConfig{})
+	monitor := insertMonitor(t, db, models.Monitor{Name: "page", URL: target.URL, Type: "http"})
+	monitor.Method = "GET"
+	monitor.HTTPMode = models.HTTPModeContent
+	monitor.AcceptedStatusCodes = "200-399"
+	monitor.MaxRedirects = 10
+	monitor.Timeout = 5
+	mc := &MonitorChecker{monitor: monitor, manager: m, ctx: m.ctx}
+
+	baseline := func() string {
+		t.Helper()
+		var hash string
+		if err := db.Get(&hash, "SELECT content_hash FROM monitors WHERE id = ?", monitor.ID); err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+
+	// An abandoned check leaves the baseline alone
+	first := mc.runCheck(context.Background())
+	if first.Status != "up" || first.ContentSnapshot == nil {
+		t.Fatalf("first check: %+v", first)
+	}
+	if hash := baseline(); hash != "" {
+		t.Errorf("unsaved check recorded baseline %q", hash)
+	}
+	if again := mc.runCheck(context.Background()); again.Message != "Content baseline recorded" {
+		t.Errorf("check after an abandoned one: %q", again.Message)
+	}
+
+	if err := mc.saveCheck(&first); err != nil {
+		t.Fatal(err)
+	}
+	if hash := baseline(); hash != first.ContentHash {
+		t.Errorf("baseline %q, want %q", hash, first.ContentHash)
+	}
+
+	unchanged := mc.runCheck(context.Background())
+	if unchanged.Message != "Content unchanged" || unchanged.ContentSnapshot != nil {
+		t.Errorf("unchanged check: %q, snapshot %v", unchanged.Message, unchanged.ContentSnapshot)
+	}
+
+	page = "<p>two</p>"
+	changed := mc.runCheck(context.Background())
+	if changed.ContentDiff == "" || changed.ContentSnapshot == nil {
+		t.Fatalf("changed check: %+v", changed)
+	}
+	if err := mc.saveCheck(&changed); err != nil {
+		t.Fatal(err)
+	}
+	if hash := baseline(); hash != changed.ContentHash {
+		t.Errorf("baseline %q, want %q", hash, changed.ContentHash)
+	}
+}
@@ -0,0 +1,29 @@
+package monitoring
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// dial connects within timeout and ties the connection to ctx: cancelling
+// ctx closes it, which interrupts any read or write in progress
+func dial(ctx context.Context, network, address string, timeout time.Duration) (net.Conn, error) {
+	conn, err := (&net.Dialer{Timeout: timeout}).DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	return &ctxConn{Conn: conn, stop: stop}, nil
+}
+
+// ctxConn is a connection closed when its context is cancelled
+type ctxConn struct {
+	net.Conn
+	stop func() bool
+}
+
+func (c *ctxConn) Close() error {
+	c.stop()
+	return c.Conn.Close()
+}
@@ -0,0 +1,166 @@
+package monitoring
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	diffContext  = 3       // unchanged lines shown around each change
+	diffMaxLines = 40      // diff lines kept in a check
+	diffMaxCells = 1000000 // largest changed region compared line by line
+)
+
+// diffOp is one line of a diff: ' ' unchanged, '-' removed or '+' added
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// unifiedDiff returns a unified diff of two texts, cut to diffMaxLines, and
+// the number of lines added and removed
+func unifiedDiff(previous, current string) (string, int, int) {
+	ops := diffLines(strings.Split(previous, "\n"), strings.Split(current, "\n"))
+
+	// Line numbers in the previous and current text before each op
+	oldLine := make([]int, len(ops)+1)
+	newLine := make([]int, len(ops)+1)
+	added, removed := 0, 0
+	for i, op := range ops {
+		oldLine[i+1], newLine[i+1] = oldLine[i], newLine[i]
+		if op.kind != '+' {
+			oldLine[i+1]++
+		}
+		if op.kind != '-' {
+			newLine[i+1]++
+		}
+		switch op.kind {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+
+	lines := []string{"--- previous", "+++ current"}
+	for i := 0; i < len(ops); {
+		start := i
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// Changes closer than twice the context share a hunk
+		end := start
+		for k := start; k < len(ops); k++ {
+			if ops[k].kind != ' ' {
+				end = k + 1
+			} else if k-end >= 2*diffContext {
+				break
+			}
+		}
+
+		from := start - diffContext
+		if from < i {
+			from = i
+		}
+		to := end + diffContext
+		if to > len(ops) {
+			to = len(ops)
+		}
+
+		lines = append(lines, fmt.Sprintf("@@ -%d,%d +%d,%d @@",
+			oldLine[from]+1, oldLine[to]-oldLine[from], newLine[from]+1, newLine[to]-newLine[from]))
+		for _, op := range ops[from:to] {
+			lines = append(lines, string(op.kind)+op.line)
+		}
+		i = to
+	}
+
+	if len(lines) > diffMaxLines {
+		more := len(lines) - diffMaxLines
+		lines = append(lines[:diffMaxLines], fmt.Sprintf("... %d more lines", more))
+	}
+	return strings.Join(lines, "\n"), added, removed
+}
+
+// diffLines compares two texts line by line. The common start and end are
+// skipped before the rest is matched by longest common subsequence.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = append(ops, diffChanged(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// diffChanged matches the changed region of two texts. Regions too large to
+// compare are shown as entirely replaced.
+func diffChanged(a, b []string) []diffOp {
+	ops := make([]diffOp, 0, len(a)+len(b))
+	if len(a)*len(b) > diffMaxCells {
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return ops
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:]
+	lcs := make([][]int32, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
@@ -0,0 +1,303 @@
+package monitoring
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"uptime-monitor/internal/models"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// typeCAA is not defined by dnsmessage; its records arrive as
+// UnknownResource
+const typeCAA = dnsmessage.Type(257)
+
+// dnsRecordTypes maps the supported record type names to query types
+var dnsRecordTypes = map[string]dnsmessage.Type{
+	"A":     dnsmessage.TypeA,
+	"AAAA":  dnsmessage.TypeAAAA,
+	"CNAME": dnsmessage.TypeCNAME,
+	"MX":    dnsmessage.TypeMX,
+	"TXT":   dnsmessage.TypeTXT,
+	"NS":    dnsmessage.TypeNS,
+	"SRV":   dnsmessage.TypeSRV,
+	"CAA":   typeCAA,
+}
+
+// ValidateDNSRecordType checks that a record type can be queried
+func ValidateDNSRecordType(recordType string) error {
+	if _, ok := dnsRecordTypes[strings.ToUpper(recordType)]; !ok {
+		return fmt.Errorf("unsupported DNS record type: %s", recordType)
+	}
+	return nil
+}
+
+func (mc *MonitorChecker) checkDNS(ctx context.Context, check *models.MonitorCheck) error {
+	name := dnsQueryName(mc.monitor.URL)
+	if name == "" {
+		return fmt.Errorf("no DNS name specified")
+	}
+
+	recordType := strings.ToUpper(mc.monitor.DNSRecordType)
+	if recordType == "" {
+		recordType = "A"
+	}
+	qtype, ok := dnsRecordTypes[recordType]
+	if !ok {
+		return fmt.Errorf("unsupported DNS record type: %s", recordType)
+	}
+
+	resolver := resolverAddress(mc.monitor.DNSResolver)
+	timeout := time.Duration(mc.monitor.Timeout) * time.Second
+
+	answers, err := queryDNS(ctx, resolver, name, qtype, timeout)
+	if err != nil {
+		return fmt.Errorf("%s %s via %s: %v", recordType, name, resolver, err)
+	}
+
+	if len(answers) == 0 {
+		return fmt.Errorf("%s %s via %s: no records", recordType, name, resolver)
+	}
+
+	got := strings.Join(answers, ", ")
+	expected := normalizeDNSValues(mc.monitor.DNSExpected, qtype)
+	if len(expected) > 0 && strings.Join(expected, ", ") != got {
+		check.Status = "down"
+		check.Message = fmt.Sprintf("%s %s answer changed: expected [%s], got [%s]",
+			recordType, name, strings.Join(expected, ", "), got)
+		return nil
+	}
+
+	check.Status = "up"
+	check.Message = fmt.Sprintf("%s %s: %s", recordType, name, got)
+	return nil
+}
+
+// dnsQueryName accepts dns://name, name or a URL and returns the host
+func dnsQueryName(target string) string {
+	if strings.Contains(target, "://") {
+		if u, err := url.Parse(target); err == nil {
+			if u.Host != "" {
+				return u.Hostname()
+			}
+			return strings.Trim(u.Path, "/")
+		}
+	}
+	return strings.TrimSpace(target)
+}
+
+// resolverAddress returns host:port for the configured resolver, falling
+// back to the first nameserver in /etc/resolv.conf
+func resolverAddress(resolver string) string {
+	resolver = strings.TrimSpace(resolver)
+	if resolver == "" {
+		resolver = systemNameserver()
+	}
+	if _, _, err := net.SplitHostPort(resolver); err != nil {
+		resolver = net.JoinHostPort(strings.Trim(resolver, "[]"), "53")
+	}
+	return resolver
+}
+
+func systemNameserver() string {
+	f, err := os.Open("/etc/resolv.conf")
+	if err != nil {
+		return "127.0.0.1"
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			return fields[1]
+		}
+	}
+	return "127.0.0.1"
+}
+
+// queryDNS sends a recursive query over UDP, retrying over TCP when the
+// answer is truncated, and returns the sorted answers of the queried type
+func queryDNS(ctx context.Context, server, name string, qtype dnsmessage.Type, timeout time.Duration) ([]string, error) {
+	qname, err := dnsmessage.NewName(fqdn(name))
+	if err != nil {
+		return nil, fmt.Errorf("invalid name: %v", err)
+	}
+
+	id := uint16(rand.Intn(1 << 16))
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: qname, Type: qtype, Class: dnsmessage.ClassINET}},
+	}
+	packet, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := exchangeDNS(ctx, "udp", server, packet, timeout)
+	if err == nil && response.Header.Truncated {
+		response, err = exchangeDNS(ctx, "tcp", server, packet, timeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Header.ID != id {
+		return nil, fmt.Errorf("mismatched response ID")
+	}
+	if response.Header.RCode != dnsmessage.RCodeSuccess {
+		return nil, fmt.Errorf("%s", rcodeName(response.Header.RCode))
+	}
+
+	var answers []string
+	for _, answer := range response.Answers {
+		if answer.Header.Type != qtype {
+			continue
+		}
+		if value := formatDNSAnswer(answer.Body); value != "" {
+			answers = append(answers, value)
+		}
+	}
+
+	return normalizeDNSValues(answers, qtype), nil
+}
+
+// rcodeName returns the conventional name of a response code
+func rcodeName(rcode dnsmessage.RCode) string {
+	switch rcode {
+	case dnsmessage.RCodeNameError:
+		return "NXDOMAIN"
+	case dnsmessage.RCodeServerFailure:
+		return "SERVFAIL"
+	case dnsmessage.RCodeRefused:
+		return "REFUSED"
+	}
+	return strings.TrimPrefix(rcode.String(), "RCode")
+}
+
+func exchangeDNS(ctx context.Context, network, server string, packet []byte, timeout time.Duration) (*dnsmessage.Message, error) {
+	conn, err := dial(ctx, network, server, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if network == "tcp" {
+		// DNS over TCP prefixes messages with their length
+		packet = append([]byte{byte(len(packet) >> 8), byte(len(packet))}, packet...)
+	}
+	if _, err := conn.Write(packet); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 65535)
+	var n int
+	if network == "tcp" {
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return nil, err
+		}
+		n = int(length[0])<<8 | int(length[1])
+		if _, err := io.ReadFull(conn, buf[:n]); err != nil {
+			return nil, err
+		}
+	} else {
+		n, err = conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var response dnsmessage.Message
+	if err := response.Unpack(buf[:n]); err != nil {
+		return nil, fmt.Errorf("invalid response: %v", err)
+	}
+	return &response, nil
+}
+
+// formatDNSAnswer renders a record the way it is written in expected values
+func formatDNSAnswer(body dnsmessage.ResourceBody) string {
+	switch r := body.(type) {
+	case *dnsmessage.AResource:
+		return net.IP(r.A[:]).String()
+	case *dnsmessage.AAAAResource:
+		return net.IP(r.AAAA[:]).String()
+	case *dnsmessage.CNAMEResource:
+		return r.CNAME.String()
+	case *dnsmessage.MXResource:
+		return fmt.Sprintf("%d %s", r.Pref, r.MX.String())
+	case *dnsmessage.TXTResource:
+		return strings.Join(r.TXT, "")
+	case *dnsmessage.NSResource:
+		return r.NS.String()
+	case *dnsmessage.SRVResource:
+		return fmt.Sprintf("%d %d %d %s", r.Priority, r.Weight, r.Port, r.Target.String())
+	case *dnsmessage.UnknownResource:
+		if r.Type == typeCAA {
+			return formatCAA(r.Data)
+		}
+	}
+	return ""
+}
+
+// formatCAA renders CAA record data as "flags tag value"
+func formatCAA(data []byte) string {
+	if len(data) < 2 || len(data) < 2+int(data[1]) {
+		return ""
+	}
+	tagEnd := 2 + int(data[1])
+	return fmt.Sprintf("%d %s %s", data[0], data[2:tagEnd], data[tagEnd:])
+}
+
+// normalizeDNSValues canonicalizes and sorts values so that answer sets
+// compare regardless of order, case of names and trailing dots
+func normalizeDNSValues(values []string, qtype dnsmessage.Type) []string {
+	normalized := []string{}
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		switch qtype {
+		case dnsmessage.TypeA, dnsmessage.TypeAAAA:
+			if ip := net.ParseIP(v); ip != nil {
+				v = ip.String()
+			}
+		case dnsmessage.TypeCNAME, dnsmessage.TypeNS, dnsmessage.TypeMX, dnsmessage.TypeSRV:
+			// Names are case-insensitive and may be written with or
+			// without the root dot
+			fields := strings.Fields(v)
+			last := len(fields) - 1
+			fields[last] = strings.ToLower(strings.TrimSuffix(fields[last], "."))
+			v = strings.Join(fields, " ")
+		case typeCAA:
+			fields := strings.Fields(strings.ReplaceAll(v, `"`, ""))
+			if len(fields) >= 2 {
+				fields[1] = strings.ToLower(fields[1])
+			}
+			v = strings.Join(fields, " ")
+		}
+
+		normalized = append(normalized, v)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
@@ -0,0 +1,191 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"uptime-monitor/internal/models"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// dnsZone maps "name type" to the records a test resolver answers with;
+// names not in the zone get NXDOMAIN
+type dnsZone map[string][]dnsmessage.ResourceBody
+
+// truncatedName is answered over UDP with the truncated flag set, so the
+// records only arrive over TCP
+const truncatedName = "big.test."
+
+// startResolver serves zone over UDP and TCP on the same local port and
+// returns its address
+func startResolver(t *testing.T, zone dnsZone) string {
+	t.Helper()
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", packetConn.LocalAddr().String())
+	if err != nil {
+		packetConn.Close()
+		t.Skipf("TCP port of the test resolver is taken: %v", err)
+	}
+	t.Cleanup(func() {
+		packetConn.Close()
+		listener.Close()
+	})
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := packetConn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if response := answerDNS(zone, buf[:n], true); response != nil {
+				packetConn.WriteTo(response, addr)
+			}
+		}
+	}()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				var length uint16
+				if err := binary.Read(conn, binary.BigEndian, &length); err != nil {
+					return
+				}
+				query := make([]byte, length)
+				if _, err := io.ReadFull(conn, query); err != nil {
+					return
+				}
+				if response := answerDNS(zone, query, false); response != nil {
+					binary.Write(conn, binary.BigEndian, uint16(len(response)))
+					conn.Write(response)
+				}
+			}()
+		}
+	}()
+	return packetConn.LocalAddr().String()
+}
+
+func answerDNS(zone dnsZone, packet []byte, udp bool) []byte {
+	var query dnsmessage.Message
+	if err := query.Unpack(packet); err != nil || len(query.Questions) != 1 {
+		return nil
+	}
+	question := query.Questions[0]
+	response := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: query.Header.ID, Response: true, RecursionAvailable: true},
+		Questions: query.Questions,
+	}
+
+	name := question.Name.String()
+	records, ok := zone[name+" "+typeName(question.Type)]
+	switch {
+	case !ok && !zone.hasName(name):
+		response.Header.RCode = dnsmessage.RCodeNameError
+	case name == truncatedName && udp:
+		response.Header.Truncated = true
+	default:
+		for _, body := range records {
+			response.Answers = append(response.Answers, dnsmessage.Resource{
+				Header: dnsmessage.ResourceHeader{Name: question.Name, Class: dnsmessage.ClassINET, TTL: 60},
+				Body:   body,
+			})
+		}
+	}
+	packed, err := response.Pack()
+	if err != nil {
+		return nil
+	}
+	return packed
+}
+
+func (z dnsZone) hasName(name string) bool {
+	for key := range z {
+		if strings.HasPrefix(key, name+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+func typeName(qtype dnsmessage.Type) string {
+	for name, t := range dnsRecordTypes {
+		if t == qtype {
+			return name
+		}
+	}
+	return qtype.String()
+}
+
+func mustName(name string) dnsmessage.Name {
+	return dnsmessage.MustNewName(name)
+}
+
+func TestDNSCheck(t *testing.T) {
+	caa := append([]byte{0, 5}, "issueletsencrypt.org"...)
+	resolver := startResolver(t, dnsZone{
+		"example.test. A": {
+			&dnsmessage.AResource{A: [4]byte{192, 0, 2, 2}},
+			&dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}},
+		},
+		"example.test. AAAA": {},
+		"example.test. MX":   {&dnsmessage.MXResource{Pref: 10, MX: mustName("Mail.Example.Test.")}},
+		"example.test. TXT":  {&dnsmessage.TXTResource{TXT: []string{"v=spf1 ", "-all"}}},
+		"example.test. CAA":  {&dnsmessage.UnknownResource{Type: typeCAA, Data: caa}},
+		"alias.test. CNAME":  {&dnsmessage.CNAMEResource{CNAME: mustName("example.test.")}},
+		"big.test. A":        {&dnsmessage.AResource{A: [4]byte{198, 51, 100, 7}}},
+	})
+
+	tests := []struct {
+		name       string
+		url        string
+		recordType string
+		expected   []string
+		status     string // empty when the check fails with err
+		message    string
+	}{
+		{"answers in any order", "example.test", "A", []string{"192.0.2.1", "192.0.2.2"}, "up", "A example.test: 192.0.2.1, 192.0.2.2"},
+		{"no expected answers", "dns://example.test", "", nil, "up", "A example.test: 192.0.2.1, 192.0.2.2"},
+		{"answer changed", "example.test", "A", []string{"192.0.2.1"}, "down", "A example.test answer changed: expected [192.0.2.1], got [192.0.2.1, 192.0.2.2]"},
+		{"names ignore case and root dot", "example.test", "MX", []string{"10 mail.example.test"}, "up", "MX example.test: 10 mail.example.test"},
+		{"txt strings joined", "example.test", "TXT", []string{"v=spf1 -all"}, "up", "TXT example.test: v=spf1 -all"},
+		{"caa with quoted value", "example.test", "CAA", []string{`0 ISSUE "letsencrypt.org"`}, "up", "CAA example.test: 0 issue letsencrypt.org"},
+		{"cname", "alias.test", "CNAME", []string{"example.test."}, "up", "CNAME alias.test: example.test"},
+		{"truncated over udp", "big.test", "A", []string{"198.51.100.7"}, "up", "A big.test: 198.51.100.7"},
+		{"no records", "example.test", "AAAA", nil, "", "no records"},
+		{"nxdomain", "missing.test", "A", nil, "", "NXDOMAIN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := models.Monitor{
+				Type: "dns", URL: tt.url, DNSResolver: resolver, Timeout: 2,
+				DNSRecordType: tt.recordType, DNSExpected: tt.expected,
+			}
+			mc := &MonitorChecker{monitor: monitor, manager: &Manager{}}
+			var check models.MonitorCheck
+			err := mc.checker().Check(context.Background(), &check)
+			if tt.status == "" {
+				if err == nil || !strings.Contains(err.Error(), tt.message) {
+					t.Fatalf("error %v, want %q", err, tt.message)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if check.Status != tt.status || check.Message != tt.message {
+				t.Errorf("got %s %q, want %s %q", check.Status, check.Message, tt.status, tt.message)
+			}
+		})
+	}
+}
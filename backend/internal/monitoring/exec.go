@@ -0,0 +1,196 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+	"uptime-monitor/internal/models"
+)
+
+// checkExec runs the monitor's command and maps its Nagios plugin exit code
+// to the check status: 0 up, 1 degraded, 2 down and anything else unknown.
+// The first output line becomes the message and perfdata the metrics.
+func (mc *MonitorChecker) checkExec(ctx context.Context, check *models.MonitorCheck) error {
+	if !mc.manager.execEnabled {
+		return fmt.Errorf("exec monitors are disabled (EXEC_MONITORS)")
+	}
+
+	timeout := time.Duration(mc.monitor.Timeout) * time.Second
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	limit := mc.monitor.MaxBodyBytes
+	if limit <= 0 {
+		limit = defaultMaxBodyBytes
+	}
+	stdout := &cappedBuffer{limit: limit}
+	stderr := &cappedBuffer{limit: limit}
+
+	// Commands get a fixed environment rather than the server's own
+	cmd := exec.CommandContext(ctx, mc.monitor.URL, mc.monitor.ExecArgs...)
+	cmd.Env = []string{"PATH=" + mc.manager.execPath, "LANG=C", "LC_ALL=C"}
+	cmd.Dir = "/"
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = time.Second // don't wait on pipes held open by children
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("command timed out after %s", timeout)
+	}
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return fmt.Errorf("failed to run command: %v", err)
+	}
+	code := cmd.ProcessState.ExitCode()
+
+	message, metrics := parsePluginOutput(stdout.String())
+	if message == "" {
+		message, _, _ = strings.Cut(strings.TrimSpace(stderr.String()), "\n")
+	}
+	if message == "" {
+		message = fmt.Sprintf("Command exited with code %d", code)
+	}
+
+	check.StatusCode = code
+	check.Message = message
+	check.Metrics = metrics
+
+	switch code {
+	case 0:
+		check.Status = "up"
+	case 1:
+		check.Status = "degraded"
+	case 2:
+		check.Status = "down"
+	default:
+		check.Status = "unknown"
+	}
+	return nil
+}
+
+// cappedBuffer keeps the first limit bytes written to it and discards the
+// rest, so a chatty command cannot exhaust memory
+type cappedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room > 0 {
+		if len(p) > room {
+			b.Buffer.Write(p[:room])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// parsePluginOutput splits Nagios plugin output into the text of the first
+// line and its perfdata. Perfdata follows a "|" on the first line and, for
+// multi-line output, the first "|" in the long text up to the end.
+func parsePluginOutput(output string) (string, models.Metrics) {
+	lines := strings.Split(strings.TrimRight(output, "\r\n"), "\n")
+	message, perfdata, _ := strings.Cut(lines[0], "|")
+
+	inPerfdata := false
+	for _, line := range lines[1:] {
+		if !inPerfdata {
+			if _, line, inPerfdata = strings.Cut(line, "|"); !inPerfdata {
+				continue
+			}
+		}
+		perfdata += " " + line
+	}
+
+	return strings.TrimSpace(message), parsePerfData(perfdata)
+}
+
+// parsePerfData parses space-separated 'label'=value[UOM];[warn];[crit];[min];[max]
+// items. Malformed items and unknown ("U") values are skipped.
+func parsePerfData(perfdata string) models.Metrics {
+	var metrics models.Metrics
+	s := strings.TrimSpace(perfdata)
+	for s != "" {
+		var label string
+		if s[0] == '\'' {
+			// Quoted labels may contain spaces; '' is a literal quote
+			end := 1
+			for ; end < len(s); end++ {
+				if s[end] == '\'' {
+					if end+1 < len(s) && s[end+1] == '\'' {
+						end++
+						continue
+					}
+					break
+				}
+			}
+			if end >= len(s) {
+				break
+			}
+			label = strings.ReplaceAll(s[1:end], "''", "'")
+			s = s[end+1:]
+		} else {
+			i := strings.IndexAny(s, "= ")
+			if i < 0 {
+				break
+			}
+			label = s[:i]
+			s = s[i:]
+		}
+
+		var item string
+		item, s, _ = strings.Cut(s, " ")
+		s = strings.TrimSpace(s)
+		if !strings.HasPrefix(item, "=") || label == "" {
+			continue
+		}
+		if metric, ok := parseMetric(label, item[1:]); ok {
+			metrics = append(metrics, metric)
+		}
+	}
+	return metrics
+}
+
+// parseMetric parses value[UOM];[warn];[crit];[min];[max]
+func parseMetric(label, item string) (models.Metric, bool) {
+	fields := strings.Split(item, ";")
+	for len(fields) < 5 {
+		fields = append(fields, "")
+	}
+
+	number := strings.TrimRightFunc(fields[0], func(r rune) bool {
+		return !strings.ContainsRune("0123456789.,", r)
+	})
+	value, err := parsePerfNumber(number)
+	if err != nil {
+		return models.Metric{}, false
+	}
+
+	metric := models.Metric{
+		Label: label,
+		Value: value,
+		Unit:  fields[0][len(number):],
+		Warn:  fields[1],
+		Crit:  fields[2],
+	}
+	if min, err := parsePerfNumber(fields[3]); err == nil {
+		metric.Min = &min
+	}
+	if max, err := parsePerfNumber(fields[4]); err == nil {
+		metric.Max = &max
+	}
+	return metric, true
+}
+
+// parsePerfNumber accepts a decimal comma, which some plugins print
+func parsePerfNumber(s string) (float64, error) {
+	return strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+}
@@ -0,0 +1,75 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"uptime-monitor/internal/models"
+)
+
+// bothFamilies is the order in which "both" checks the address families
+var bothFamilies = []string{models.AddressFamilyIPv6, models.AddressFamilyIPv4}
+
+// familyNames label the families in check messages
+var familyNames = map[string]string{
+	models.AddressFamilyIPv4: "IPv4",
+	models.AddressFamilyIPv6: "IPv6",
+}
+
+// statusRank orders statuses from best to worst
+var statusRank = map[string]int{"up": 0, "degraded": 1, "unknown": 2, "down": 3, "timeout": 3}
+
+// dialNetwork restricts a network such as "tcp" to the monitor's address
+// family, e.g. "tcp6"
+func (mc *MonitorChecker) dialNetwork(network string) string {
+	switch mc.monitor.AddressFamily {
+	case models.AddressFamilyIPv4:
+		return network + "4"
+	case models.AddressFamilyIPv6:
+		return network + "6"
+	}
+	return network
+}
+
+// checkFamilies runs check once, or with address family "both" once per
+// family. Each family's outcome is recorded on the check; the worst one
+// decides the status, and its details are the ones kept.
+func (mc *MonitorChecker) checkFamilies(ctx context.Context, check *models.MonitorCheck, run func(*MonitorChecker, context.Context, *models.MonitorCheck) error) error {
+	if mc.monitor.AddressFamily != models.AddressFamilyBoth {
+		return run(mc, ctx, check)
+	}
+
+	var worst models.MonitorCheck
+	var results models.FamilyResults
+	var messages []string
+	for i, family := range bothFamilies {
+		fc := &MonitorChecker{monitor: mc.monitor, manager: mc.manager, dryRun: mc.dryRun}
+		fc.monitor.AddressFamily = family
+
+		start := time.Now()
+		result := models.MonitorCheck{MonitorID: check.MonitorID, CheckedAt: check.CheckedAt}
+		if err := run(fc, ctx, &result); err != nil {
+			result.Status = "down"
+			result.Message = err.Error()
+		} else if result.Status == "" {
+			result.Status = "up"
+		}
+
+		results = append(results, models.FamilyResult{
+			Family:       family,
+			Status:       result.Status,
+			ResponseTime: int(time.Since(start).Milliseconds()),
+			Message:      result.Message,
+		})
+		messages = append(messages, fmt.Sprintf("%s: %s", familyNames[family], result.Message))
+		if i == 0 || statusRank[result.Status] > statusRank[worst.Status] {
+			worst = result
+		}
+	}
+
+	*check = worst
+	check.Families = results
+	check.Message = strings.Join(messages, "; ")
+	return nil
+}
@@ -0,0 +1,45 @@
+package monitoring
+
+import (
+	"context"
+	"testing"
+	"uptime-monitor/internal/models"
+)
+
+func TestCheckFamiliesKeepsWorstStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		ipv4, ipv6 string
+		want       string
+	}{
+		{"both up", "up", "up", "up"},
+		{"degraded over up", "up", "degraded", "degraded"},
+		{"down over degraded", "down", "degraded", "down"},
+		{"timeout over up", "up", "timeout", "timeout"},
+		{"timeout before up", "timeout", "up", "timeout"},
+		{"IPv6 first of down and timeout", "down", "timeout", "timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := &MonitorChecker{monitor: models.Monitor{AddressFamily: models.AddressFamilyBoth}}
+			statuses := map[string]string{models.AddressFamilyIPv4: tt.ipv4, models.AddressFamilyIPv6: tt.ipv6}
+
+			var check models.MonitorCheck
+			err := mc.checkFamilies(context.Background(), &check, func(fc *MonitorChecker, _ context.Context, result *models.MonitorCheck) error {
+				result.Status = statuses[fc.monitor.AddressFamily]
+				result.Message = result.Status
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if check.Status != tt.want {
+				t.Errorf("status %q, want %q", check.Status, tt.want)
+			}
+			if len(check.Families) != 2 {
+				t.Errorf("%d family results, want 2", len(check.Families))
+			}
+		})
+	}
+}
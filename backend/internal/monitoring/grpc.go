@@ -0,0 +1,81 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"uptime-monitor/internal/models"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// checkGRPC calls grpc.health.v1.Health/Check for the monitor's service
+// name; an empty name asks about the server as a whole
+func (mc *MonitorChecker) checkGRPC(ctx context.Context, check *models.MonitorCheck) error {
+	address, useTLS, err := grpcTarget(mc.monitor.URL)
+	if err != nil {
+		return err
+	}
+
+	transport := insecure.NewCredentials()
+	if useTLS || mc.monitor.TLSMode == models.TLSModeTLS {
+		config, err := mc.tlsConfig(check)
+		if err != nil {
+			return err
+		}
+		transport = credentials.NewTLS(config)
+	}
+
+	timeout := time.Duration(mc.monitor.Timeout) * time.Second
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, address, grpc.WithTransportCredentials(transport))
+	if err != nil {
+		return fmt.Errorf("gRPC dial failed to %s: %v", address, err)
+	}
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: mc.monitor.GRPCService})
+	if err != nil {
+		st := status.Convert(err)
+		return fmt.Errorf("health check failed: %s: %s", st.Code(), st.Message())
+	}
+
+	check.Message = fmt.Sprintf("Health check: %s", resp.GetStatus())
+	if mc.monitor.GRPCService != "" {
+		check.Message = fmt.Sprintf("Health check for %s: %s", mc.monitor.GRPCService, resp.GetStatus())
+	}
+
+	switch resp.GetStatus() {
+	case healthpb.HealthCheckResponse_SERVING:
+		check.Status = "up"
+	case healthpb.HealthCheckResponse_NOT_SERVING:
+		check.Status = "down"
+	default:
+		check.Status = "unknown"
+	}
+	return nil
+}
+
+// grpcTarget accepts grpc://host:port, grpcs://host:port (TLS) or host:port
+func grpcTarget(target string) (string, bool, error) {
+	if !strings.Contains(target, "://") {
+		return target, false, nil
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid gRPC URL: %v", err)
+	}
+	if u.Port() == "" {
+		return "", false, fmt.Errorf("gRPC check requires host:port format, got: %s", u.Host)
+	}
+	return u.Host, u.Scheme == "grpcs", nil
+}
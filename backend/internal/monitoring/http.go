@@ -0,0 +1,205 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"time"
+	"uptime-monitor/internal/models"
+)
+
+func (mc *MonitorChecker) checkHTTP(ctx context.Context, check *models.MonitorCheck) error {
+	req, err := mc.newRequest(ctx)
+	if err != nil {
+		return err
+	}
+
+	timing := &httpTiming{}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), timing.trace()))
+
+	client, err := mc.httpClient(check)
+	if err != nil {
+		return err
+	}
+	defer client.CloseIdleConnections()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		timing.record(check, time.Time{})
+		return err
+	}
+	defer resp.Body.Close()
+
+	// The body is always read so that the transfer time covers it
+	body, readErr := mc.readLimited(resp.Body)
+	timing.record(check, time.Now())
+
+	check.StatusCode = resp.StatusCode
+
+	if !statusAccepted(mc.monitor.AcceptedStatusCodes, resp.StatusCode) {
+		check.Status = "down"
+		check.Message = fmt.Sprintf("HTTP %d", resp.StatusCode)
+		return nil
+	}
+
+	if mc.monitor.Keyword == "" && mc.monitor.HTTPMode == models.HTTPModeStatus {
+		check.Status = "up"
+		check.Message = "OK"
+		return nil
+	}
+
+	if readErr != nil {
+		return fmt.Errorf("failed to read response body: %v", readErr)
+	}
+
+	failure, err := mc.assertBody(body)
+	if err != nil {
+		return err
+	}
+	if failure != "" {
+		check.Status = "down"
+		check.Message = failure
+		return nil
+	}
+
+	if mc.monitor.HTTPMode == models.HTTPModeContent {
+		return mc.compareContent(check, body)
+	}
+
+	check.Status = "up"
+	check.Message = "OK"
+	return nil
+}
+
+// httpClient builds a client with the monitor's timeout, address family,
+// proxy, TLS settings and redirect policy. Each check gets its own
+// transport, so callers close its idle connections when they are done.
+func (mc *MonitorChecker) httpClient(check *models.MonitorCheck) (*http.Client, error) {
+	proxy, err := mc.proxy()
+	if err != nil {
+		return nil, err
+	}
+	config, err := mc.tlsConfig(check)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{}
+	return &http.Client{
+		Timeout: time.Duration(mc.monitor.Timeout) * time.Second,
+		Transport: &http.Transport{
+			Proxy: proxy,
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+				return dialer.DialContext(ctx, mc.dialNetwork(network), address)
+			},
+			TLSClientConfig: config,
+		},
+		CheckRedirect: mc.checkRedirect,
+	}, nil
+}
+
+// assertBody runs the keyword and mode-specific assertions on a response
+// body and returns the first failure description
+func (mc *MonitorChecker) assertBody(body []byte) (string, error) {
+	if mc.monitor.Keyword != "" {
+		if failure, err := mc.matchKeyword(body); err != nil || failure != "" {
+			return failure, err
+		}
+	}
+
+	if mc.monitor.HTTPMode == models.HTTPModeJSON {
+		return assertJSON(body, mc.monitor.JSONAssertions)
+	}
+
+	return "", nil
+}
+
+// newRequest builds the request described by the monitor's method, headers,
+// body and authentication settings
+func (mc *MonitorChecker) newRequest(ctx context.Context) (*http.Request, error) {
+	method := mc.monitor.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var body io.Reader
+	if mc.monitor.Body != "" {
+		body = strings.NewReader(mc.monitor.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, mc.monitor.URL, body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %v", err)
+	}
+
+	for name, value := range mc.monitor.Headers {
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+
+	switch mc.monitor.AuthMethod {
+	case models.AuthBasic:
+		req.SetBasicAuth(mc.monitor.AuthUsername, mc.monitor.AuthPassword)
+	case models.AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+mc.monitor.AuthToken)
+	}
+
+	return req, nil
+}
+
+// checkRedirect applies the monitor's redirect policy
+func (mc *MonitorChecker) checkRedirect(req *http.Request, via []*http.Request) error {
+	if mc.monitor.FollowRedirects != nil && !*mc.monitor.FollowRedirects {
+		return http.ErrUseLastResponse
+	}
+
+	maxRedirects := mc.monitor.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = 10
+	}
+	if len(via) > maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	return nil
+}
+
+// tlsConfig verifies the server certificate itself so that the chain is
+// recorded on the check even when verification fails or is turned off. The
+// monitor's CA bundle replaces the system roots.
+func (mc *MonitorChecker) tlsConfig(check *models.MonitorCheck) (*tls.Config, error) {
+	certificates, err := clientCertificates(mc.monitor)
+	if err != nil {
+		return nil, err
+	}
+	roots, err := caBundle(mc.monitor.TLSCABundle)
+	if err != nil {
+		return nil, err
+	}
+	if roots == nil {
+		roots = mc.manager.rootCAs
+	}
+
+	return &tls.Config{
+		Certificates:       certificates,
+		InsecureSkipVerify: true, // verified in VerifyConnection below
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			// Only the first connection describes the monitored host;
+			// later ones come from redirects
+			if check.CertExpiresAt == nil {
+				recordCertificate(check, cs.PeerCertificates)
+			}
+			if mc.monitor.TLSIgnoreErrors {
+				return nil
+			}
+			return verifyCertificate(cs, roots)
+		},
+	}, nil
+}
@@ -0,0 +1,51 @@
+package monitoring
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"uptime-monitor/internal/models"
+)
+
+func TestHTTPCheckClosesConnections(t *testing.T) {
+	var open atomic.Int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		switch state {
+		case http.StateNew:
+			open.Add(1)
+		case http.StateClosed, http.StateHijacked:
+			open.Add(-1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	monitors := []models.Monitor{
+		{Type: "http", URL: server.URL, Timeout: 5, AcceptedStatusCodes: "200"},
+		{Type: "transaction", Timeout: 5, TransactionSteps: models.TransactionSteps{
+			{URL: server.URL, Method: "GET"}, {URL: server.URL, Method: "GET"},
+		}},
+	}
+	for _, monitor := range monitors {
+		mc := &MonitorChecker{monitor: monitor, manager: &Manager{}}
+		var check models.MonitorCheck
+		if err := mc.checker().Check(context.Background(), &check); err != nil || check.Status != "up" {
+			t.Fatalf("%s check: %v %+v", monitor.Type, err, check)
+		}
+
+		deadline := time.Now().Add(time.Second)
+		for open.Load() != 0 {
+			if time.Now().After(deadline) {
+				t.Fatalf("%s check left %d connections open", monitor.Type, open.Load())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package monitoring
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"uptime-monitor/internal/models"
+)
+
+// JSON assertion operators
+const (
+	OpEqual    = "=="
+	OpNotEqual = "!="
+	OpLess     = "<"
+	OpGreater  = ">"
+	OpContains = "contains"
+	OpExists   = "exists"
+)
+
+// ValidateJSONAssertion checks that an assertion can be evaluated
+func ValidateJSONAssertion(a models.JSONAssertion) error {
+	if _, err := parsePath(a.Path); err != nil {
+		return err
+	}
+
+	switch a.Operator {
+	case OpEqual, OpNotEqual, OpContains, OpExists:
+	case OpLess, OpGreater:
+		if _, err := strconv.ParseFloat(a.Expected, 64); err != nil {
+			return fmt.Errorf("operator %s requires a numeric expected value", a.Operator)
+		}
+	default:
+		return fmt.Errorf("unknown operator: %s", a.Operator)
+	}
+	return nil
+}
+
+// ValidateJSONPath checks that a path expression can be parsed
+func ValidateJSONPath(path string) error {
+	_, err := parsePath(path)
+	return err
+}
+
+// assertJSON evaluates the assertions against a JSON body and returns a
+// description of every failure, or "" when all of them hold
+func assertJSON(body []byte, assertions []models.JSONAssertion) (string, error) {
+	doc, err := decodeJSON(body)
+	if err != nil {
+		return "", fmt.Errorf("response is not valid JSON: %v", err)
+	}
+
+	return evaluateAssertions("JSON", doc, assertions), nil
+}
+
+// evaluateAssertions evaluates the assertions against a decoded document and
+// returns a description of every failure, or "" when all of them hold
+func evaluateAssertions(kind string, doc interface{}, assertions []models.JSONAssertion) string {
+	var failures []string
+	for _, a := range assertions {
+		if failure := evaluateJSONAssertion(doc, a); failure != "" {
+			failures = append(failures, failure)
+		}
+	}
+
+	if len(failures) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d of %d %s assertions failed: %s",
+		len(failures), len(assertions), kind, strings.Join(failures, "; "))
+}
+
+func decodeJSON(body []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// evaluateJSONAssertion returns a failure description, or "" on success
+func evaluateJSONAssertion(doc interface{}, a models.JSONAssertion) string {
+	label := fmt.Sprintf("%s %s %q", a.Path, a.Operator, a.Expected)
+	if a.Operator == OpExists {
+		label = fmt.Sprintf("%s exists", a.Path)
+	}
+
+	segments, err := parsePath(a.Path)
+	if err != nil {
+		return fmt.Sprintf("%s (%v)", label, err)
+	}
+
+	actual, found := lookupJSON(doc, segments)
+	if !found {
+		return fmt.Sprintf("%s (path not found)", label)
+	}
+
+	var ok bool
+	switch a.Operator {
+	case OpExists:
+		ok = true
+	case OpEqual:
+		ok = jsonEqual(actual, a.Expected)
+	case OpNotEqual:
+		ok = !jsonEqual(actual, a.Expected)
+	case OpLess, OpGreater:
+		value, isNumber := jsonNumber(actual)
+		expected, err := strconv.ParseFloat(a.Expected, 64)
+		if isNumber && err == nil {
+			ok = (a.Operator == OpLess && value < expected) || (a.Operator == OpGreater && value > expected)
+		}
+	case OpContains:
+		ok = jsonContains(actual, a.Expected)
+	default:
+		return fmt.Sprintf("%s (unknown operator)", label)
+	}
+
+	if ok {
+		return ""
+	}
+	return fmt.Sprintf("%s (got %s)", label, describeJSON(actual))
+}
+
+// parsePath splits gjson-style (a.b.0, a.#) and JSONPath ($.a.b[0],
+// $['a']) expressions into segments
+func parsePath(path string) ([]string, error) {
+	p := strings.TrimSpace(path)
+	p = strings.TrimPrefix(p, "$")
+	p = strings.TrimPrefix(p, ".")
+	if p == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+
+	var segments []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			segments = append(segments, current.String())
+			current.Reset()
+		}
+	}
+
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; c {
+		case '\\':
+			if i+1 < len(p) {
+				i++
+				current.WriteByte(p[i])
+			}
+		case '.':
+			flush()
+		case '[':
+			flush()
+			end := strings.IndexByte(p[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in path %q", path)
+			}
+			key := strings.Trim(p[i+1:i+end], `'"`)
+			segments = append(segments, key)
+			i += end
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	return segments, nil
+}
+
+// lookupJSON walks a decoded document. "#" yields the length of an array.
+func lookupJSON(doc interface{}, segments []string) (interface{}, bool) {
+	current := doc
+	for _, segment := range segments {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[segment]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []interface{}:
+			if segment == "#" {
+				current = json.Number(strconv.Itoa(len(node)))
+				continue
+			}
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func jsonNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// jsonEqual compares a decoded value with an expected value written as
+// plain text: strings compare verbatim, numbers numerically, and objects or
+// arrays by their JSON encoding
+func jsonEqual(actual interface{}, expected string) bool {
+	switch v := actual.(type) {
+	case string:
+		return v == expected
+	case json.Number:
+		a, errA := v.Float64()
+		e, errE := strconv.ParseFloat(expected, 64)
+		if errA != nil || errE != nil {
+			return v.String() == expected
+		}
+		return a == e
+	case bool:
+		return strconv.FormatBool(v) == expected
+	case nil:
+		return expected == "null"
+	}
+
+	expectedDoc, err := decodeJSON([]byte(expected))
+	if err != nil {
+		return false
+	}
+	return describeJSON(actual) == describeJSON(expectedDoc)
+}
+
+// jsonContains checks substrings of strings, elements of arrays and keys of
+// objects
+func jsonContains(actual interface{}, expected string) bool {
+	switch v := actual.(type) {
+	case string:
+		return strings.Contains(v, expected)
+	case []interface{}:
+		for _, element := range v {
+			if jsonEqual(element, expected) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		_, ok := v[expected]
+		return ok
+	}
+	return false
+}
+
+func describeJSON(value interface{}) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	if len(b) > snippetLength {
+		return string(b[:snippetLength]) + "…"
+	}
+	return string(b)
+}
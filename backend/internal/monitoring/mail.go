@@ -0,0 +1,420 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+	"uptime-monitor/internal/models"
+)
+
+// mailPorts are the default plaintext and implicit TLS ports per protocol
+var mailPorts = map[string][2]string{
+	StartTLSSMTP: {"25", "465"},
+	StartTLSIMAP: {"143", "993"},
+	StartTLSPOP3: {"110", "995"},
+}
+
+// IsMailType reports whether a monitor type is checked with a mail session
+func IsMailType(monitorType string) bool {
+	_, ok := mailPorts[monitorType]
+	return ok
+}
+
+// checkMail walks through a mail session: greeting, capabilities, optional
+// STARTTLS and login, and for SMTP an envelope probe. Each step is timed.
+func (mc *MonitorChecker) checkMail(ctx context.Context, check *models.MonitorCheck) error {
+	protocol := mc.monitor.Type
+	address, implicitTLS, err := mailTarget(mc.monitor.URL, protocol)
+	if err != nil {
+		return err
+	}
+
+	timeout := time.Duration(mc.monitor.Timeout) * time.Second
+	var conn net.Conn
+	err = runStep(check, "connect", func() (err error) {
+		conn, err = dial(ctx, "tcp", address, timeout)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("connection failed to %s: %v", address, err)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	host, _, _ := net.SplitHostPort(address)
+	s := &mailSession{mc: mc, check: check, host: host, conn: conn, tc: newTextConn(conn)}
+	defer func() { s.conn.Close() }()
+
+	if implicitTLS || mc.monitor.TLSMode == models.TLSModeTLS {
+		if err := s.handshake(); err != nil {
+			return err
+		}
+	}
+
+	var message string
+	switch protocol {
+	case StartTLSSMTP:
+		message, err = s.smtp()
+	case StartTLSIMAP:
+		message, err = s.imap()
+	case StartTLSPOP3:
+		message, err = s.pop3()
+	}
+	if err != nil {
+		// Every failure comes from a step, which names where it stopped
+		failed := check.Steps[len(check.Steps)-1].Name
+		return fmt.Errorf("%s %s failed: %v", strings.ToUpper(protocol), failed, err)
+	}
+
+	check.Status = "up"
+	check.Message = message
+	return nil
+}
+
+// mailTarget accepts proto://host[:port], protos://host[:port] for implicit
+// TLS, or host:port
+func mailTarget(target, protocol string) (string, bool, error) {
+	if !strings.Contains(target, "://") {
+		if _, _, err := net.SplitHostPort(target); err != nil {
+			return "", false, fmt.Errorf("%s check requires host:port format, got: %s", protocol, target)
+		}
+		return target, false, nil
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid %s URL: %v", protocol, err)
+	}
+
+	implicitTLS := false
+	switch u.Scheme {
+	case protocol:
+	case protocol + "s":
+		implicitTLS = true
+	default:
+		return "", false, fmt.Errorf("unsupported URL scheme for %s monitor: %s", protocol, u.Scheme)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = mailPorts[protocol][0]
+		if implicitTLS {
+			port = mailPorts[protocol][1]
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port), implicitTLS, nil
+}
+
+// mailSession holds the state of one mail check
+type mailSession struct {
+	mc     *MonitorChecker
+	check  *models.MonitorCheck
+	host   string
+	conn   net.Conn
+	tc     *textConn
+	secure bool
+}
+
+func (s *mailSession) step(name string, fn func() error) error {
+	return runStep(s.check, name, fn)
+}
+
+func (s *mailSession) wantsStartTLS() bool {
+	return strings.HasPrefix(s.mc.monitor.TLSMode, models.TLSModeStartTLS)
+}
+
+// errPlaintextLogin is returned instead of sending credentials unencrypted
+var errPlaintextLogin = errors.New("refusing to send credentials without TLS")
+
+// handshake wraps the connection in TLS and records the certificate
+func (s *mailSession) handshake() error {
+	return s.step("tls", func() error {
+		config, err := s.mc.tlsConfig(s.check)
+		if err != nil {
+			return err
+		}
+		config.ServerName = s.host
+
+		tlsConn := tls.Client(s.conn, config)
+		if err := tlsConn.Handshake(); err != nil {
+			return fmt.Errorf("TLS handshake failed: %v", err)
+		}
+
+		tag := s.tc.tag
+		s.conn = tlsConn
+		s.tc = newTextConn(tlsConn)
+		s.tc.tag = tag
+		s.secure = true
+		return nil
+	})
+}
+
+func (s *mailSession) smtp() (string, error) {
+	var banner string
+	err := s.step("banner", func() error {
+		_, lines, err := s.tc.smtpReply(220)
+		if len(lines) > 0 {
+			banner = lines[0]
+		}
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	extensions, err := s.smtpEHLO()
+	if err != nil {
+		return "", err
+	}
+
+	if s.wantsStartTLS() {
+		if err := s.step("starttls", func() error {
+			if _, ok := extensions["STARTTLS"]; !ok {
+				return errors.New("server does not offer STARTTLS")
+			}
+			_, _, err := s.tc.smtpCommand(220, "STARTTLS")
+			return err
+		}); err != nil {
+			return "", err
+		}
+		if err := s.handshake(); err != nil {
+			return "", err
+		}
+		if extensions, err = s.smtpEHLO(); err != nil {
+			return "", err
+		}
+	}
+
+	if s.mc.monitor.AuthUsername != "" {
+		if err := s.step("auth", func() error {
+			if !s.secure {
+				return errPlaintextLogin
+			}
+			return s.smtpAuth(extensions["AUTH"])
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	message := fmt.Sprintf("SMTP ready: %s", banner)
+	if s.mc.monitor.SMTPFrom != "" || s.mc.monitor.SMTPTo != "" {
+		if err := s.step("mail_from", func() error {
+			_, _, err := s.tc.smtpCommand(250, "MAIL FROM:<%s>", s.mc.monitor.SMTPFrom)
+			return err
+		}); err != nil {
+			return "", err
+		}
+
+		if s.mc.monitor.SMTPTo != "" {
+			if err := s.step("rcpt_to", func() error {
+				_, _, err := s.tc.smtpCommand(250, "RCPT TO:<%s>", s.mc.monitor.SMTPTo)
+				return err
+			}); err != nil {
+				return "", err
+			}
+			message += fmt.Sprintf(", accepts mail for %s", s.mc.monitor.SMTPTo)
+		}
+
+		s.tc.smtpCommand(250, "RSET")
+	}
+
+	s.tc.writeLine("QUIT")
+	return message, nil
+}
+
+// smtpEHLO returns the advertised extensions keyed by their keyword
+func (s *mailSession) smtpEHLO() (map[string]string, error) {
+	extensions := map[string]string{}
+	err := s.step("ehlo", func() error {
+		_, lines, err := s.tc.smtpCommand(250, "EHLO %s", heloName)
+		if err != nil {
+			return err
+		}
+		// The first line is the server's greeting
+		for _, line := range lines[1:] {
+			keyword, params, _ := strings.Cut(line, " ")
+			extensions[strings.ToUpper(keyword)] = params
+		}
+		return nil
+	})
+	return extensions, err
+}
+
+// smtpAuth logs in with AUTH PLAIN, or AUTH LOGIN when only that is offered
+func (s *mailSession) smtpAuth(mechanisms string) error {
+	user, pass := s.mc.monitor.AuthUsername, s.mc.monitor.AuthPassword
+	encode := base64.StdEncoding.EncodeToString
+
+	offered := strings.Fields(strings.ToUpper(mechanisms))
+	if !containsString(offered, "PLAIN") && containsString(offered, "LOGIN") {
+		if _, _, err := s.tc.smtpCommand(334, "AUTH LOGIN"); err != nil {
+			return err
+		}
+		if _, _, err := s.tc.smtpCommand(334, "%s", encode([]byte(user))); err != nil {
+			return err
+		}
+		_, _, err := s.tc.smtpCommand(235, "%s", encode([]byte(pass)))
+		return err
+	}
+
+	_, _, err := s.tc.smtpCommand(235, "AUTH PLAIN %s", encode([]byte("\x00"+user+"\x00"+pass)))
+	return err
+}
+
+func (s *mailSession) imap() (string, error) {
+	var banner string
+	if err := s.step("banner", func() (err error) {
+		banner, err = s.tc.imapGreeting()
+		return err
+	}); err != nil {
+		return "", err
+	}
+
+	capabilities, err := s.imapCapabilities()
+	if err != nil {
+		return "", err
+	}
+
+	if s.wantsStartTLS() {
+		if err := s.step("starttls", func() error {
+			if !capabilities["STARTTLS"] {
+				return errors.New("server does not offer STARTTLS")
+			}
+			_, err := s.tc.imapCommand("STARTTLS")
+			return err
+		}); err != nil {
+			return "", err
+		}
+		if err := s.handshake(); err != nil {
+			return "", err
+		}
+		if capabilities, err = s.imapCapabilities(); err != nil {
+			return "", err
+		}
+	}
+
+	if s.mc.monitor.AuthUsername != "" {
+		if err := s.step("login", func() error {
+			if !s.secure {
+				return errPlaintextLogin
+			}
+			if capabilities["LOGINDISABLED"] {
+				return errors.New("server has disabled LOGIN")
+			}
+			_, err := s.tc.imapCommand("LOGIN %s %s", imapQuote(s.mc.monitor.AuthUsername), imapQuote(s.mc.monitor.AuthPassword))
+			return err
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	s.tc.imapCommand("LOGOUT")
+	return fmt.Sprintf("IMAP ready: %s", strings.TrimPrefix(banner, "* ")), nil
+}
+
+// imapCapabilities asks for the server's CAPABILITY list
+func (s *mailSession) imapCapabilities() (map[string]bool, error) {
+	capabilities := map[string]bool{}
+	err := s.step("capability", func() error {
+		untagged, err := s.tc.imapCommand("CAPABILITY")
+		if err != nil {
+			return err
+		}
+		for _, line := range untagged {
+			if list, ok := strings.CutPrefix(line, "* CAPABILITY "); ok {
+				for _, capability := range strings.Fields(list) {
+					capabilities[strings.ToUpper(capability)] = true
+				}
+			}
+		}
+		return nil
+	})
+	return capabilities, err
+}
+
+// imapQuote returns s as an IMAP quoted string
+func imapQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func (s *mailSession) pop3() (string, error) {
+	var banner string
+	if err := s.step("banner", func() (err error) {
+		banner, err = s.tc.pop3Reply()
+		return err
+	}); err != nil {
+		return "", err
+	}
+
+	if err := s.pop3Capabilities(); err != nil {
+		return "", err
+	}
+
+	// CAPA is optional in POP3, so STLS is attempted without checking it
+	if s.wantsStartTLS() {
+		if err := s.step("starttls", func() error {
+			_, err := s.tc.pop3Command("STLS")
+			return err
+		}); err != nil {
+			return "", err
+		}
+		if err := s.handshake(); err != nil {
+			return "", err
+		}
+		if err := s.pop3Capabilities(); err != nil {
+			return "", err
+		}
+	}
+
+	if s.mc.monitor.AuthUsername != "" {
+		if err := s.step("login", func() error {
+			if !s.secure {
+				return errPlaintextLogin
+			}
+			if _, err := s.tc.pop3Command("USER %s", s.mc.monitor.AuthUsername); err != nil {
+				return err
+			}
+			_, err := s.tc.pop3Command("PASS %s", s.mc.monitor.AuthPassword)
+			return err
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	s.tc.pop3Command("QUIT")
+	return fmt.Sprintf("POP3 ready: %s", strings.TrimPrefix(banner, "+OK ")), nil
+}
+
+// pop3Capabilities reads the CAPA list; servers without CAPA answer -ERR,
+// which is not a failure
+func (s *mailSession) pop3Capabilities() error {
+	return s.step("capa", func() error {
+		if err := s.tc.writeLine("CAPA"); err != nil {
+			return err
+		}
+		line, err := s.tc.readLine()
+		if err != nil || !strings.HasPrefix(line, "+OK") {
+			return err
+		}
+		for line != "." {
+			if line, err = s.tc.readLine(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,386 @@
+package monitoring
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"uptime-monitor/internal/models"
+)
+
+// fakeMailConn is the server side of one session with a fake mail server
+type fakeMailConn struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+	cert   tls.Certificate
+}
+
+// send writes reply lines
+func (c *fakeMailConn) send(lines ...string) {
+	for _, line := range lines {
+		io.WriteString(c.conn, line+"\r\n")
+	}
+}
+
+// expect reads a command and fails the test unless it is want
+func (c *fakeMailConn) expect(want string) bool {
+	line, err := c.reader.ReadString('\n')
+	if got := strings.TrimRight(line, "\r\n"); err != nil || got != want {
+		c.t.Errorf("server got %q (%v), want %q", got, err, want)
+		return false
+	}
+	return true
+}
+
+// expectClose fails the test if the client sends anything more
+func (c *fakeMailConn) expectClose() {
+	if line, err := c.reader.ReadString('\n'); err != io.EOF {
+		c.t.Errorf("server got %q (%v), want the connection closed", line, err)
+	}
+}
+
+// upgrade completes the server side of STARTTLS
+func (c *fakeMailConn) upgrade() bool {
+	tlsConn := tls.Server(c.conn, &tls.Config{Certificates: []tls.Certificate{c.cert}})
+	if err := tlsConn.Handshake(); err != nil {
+		c.t.Errorf("server TLS handshake: %v", err)
+		return false
+	}
+	c.conn = tlsConn
+	c.reader = bufio.NewReader(tlsConn)
+	return true
+}
+
+// startMailServer runs script for each connection and returns the address
+func startMailServer(t *testing.T, cert tls.Certificate, script func(c *fakeMailConn)) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	t.Cleanup(func() {
+		listener.Close()
+		wg.Wait()
+	})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.SetDeadline(time.Now().Add(5 * time.Second))
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c := &fakeMailConn{t: t, conn: conn, reader: bufio.NewReader(conn), cert: cert}
+				script(c)
+				c.conn.Close()
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestMailCheck(t *testing.T) {
+	cert := selfSigned(t, "Mail Test CA", time.Now().Add(30*24*time.Hour))
+	plain := base64.StdEncoding.EncodeToString([]byte("\x00me\x00secret"))
+
+	smtpGreeting := func(c *fakeMailConn, extensions ...string) bool {
+		c.send("220 mail.test ESMTP ready")
+		if !c.expect("EHLO " + heloName) {
+			return false
+		}
+		lines := append([]string{"mail.test"}, extensions...)
+		for i, line := range lines {
+			sep := "-"
+			if i == len(lines)-1 {
+				sep = " "
+			}
+			c.send("250" + sep + line)
+		}
+		return true
+	}
+
+	tests := []struct {
+		name    string
+		monitor models.Monitor
+		script  func(c *fakeMailConn)
+		err     string // expected failure; empty when the check passes
+		message string
+		steps   string // names of the steps taken
+	}{
+		{
+			name:    "smtp banner",
+			monitor: models.Monitor{Type: "smtp"},
+			script: func(c *fakeMailConn) {
+				if smtpGreeting(c, "PIPELINING") {
+					c.expect("QUIT")
+				}
+			},
+			message: "SMTP ready: mail.test ESMTP ready",
+			steps:   "connect banner ehlo",
+		},
+		{
+			name: "smtp starttls, login and envelope",
+			monitor: models.Monitor{Type: "smtp", TLSMode: models.TLSModeStartTLS, AuthUsername: "me", AuthPassword: "secret",
+				SMTPFrom: "monitor@example.test", SMTPTo: "postmaster@example.test"},
+			script: func(c *fakeMailConn) {
+				if !smtpGreeting(c, "STARTTLS") || !c.expect("STARTTLS") {
+					return
+				}
+				c.send("220 go ahead")
+				if !c.upgrade() || !c.expect("EHLO "+heloName) {
+					return
+				}
+				c.send("250-mail.test", "250 AUTH LOGIN PLAIN")
+				steps := []struct{ command, reply string }{
+					{"AUTH PLAIN " + plain, "235 ok"},
+					{"MAIL FROM:<monitor@example.test>", "250 ok"},
+					{"RCPT TO:<postmaster@example.test>", "250 ok"},
+					{"RSET", "250 flushed"},
+				}
+				for _, step := range steps {
+					if !c.expect(step.command) {
+						return
+					}
+					c.send(step.reply)
+				}
+				c.expect("QUIT")
+			},
+			message: "SMTP ready: mail.test ESMTP ready, accepts mail for postmaster@example.test",
+			steps:   "connect banner ehlo starttls tls ehlo auth mail_from rcpt_to",
+		},
+		{
+			name:    "smtp auth login",
+			monitor: models.Monitor{Type: "smtp", TLSMode: models.TLSModeTLS, AuthUsername: "me", AuthPassword: "secret"},
+			script: func(c *fakeMailConn) {
+				if !c.upgrade() || !smtpGreeting(c, "AUTH LOGIN") {
+					return
+				}
+				for _, step := range [][2]string{{"AUTH LOGIN", "334 VXNlcm5hbWU6"}, {"bWU=", "334 UGFzc3dvcmQ6"}, {"c2VjcmV0", "235 ok"}} {
+					if !c.expect(step[0]) {
+						return
+					}
+					c.send(step[1])
+				}
+				c.expect("QUIT")
+			},
+			message: "SMTP ready: mail.test ESMTP ready",
+			steps:   "connect tls banner ehlo auth",
+		},
+		{
+			name:    "smtp refuses plaintext login",
+			monitor: models.Monitor{Type: "smtp", AuthUsername: "me", AuthPassword: "secret"},
+			script: func(c *fakeMailConn) {
+				if smtpGreeting(c, "AUTH PLAIN") {
+					c.expectClose()
+				}
+			},
+			err: "SMTP auth failed: refusing to send credentials without TLS",
+		},
+		{
+			name:    "smtp without starttls",
+			monitor: models.Monitor{Type: "smtp", TLSMode: models.TLSModeStartTLS},
+			script: func(c *fakeMailConn) {
+				if smtpGreeting(c, "PIPELINING") {
+					c.expectClose()
+				}
+			},
+			err: "SMTP starttls failed: server does not offer STARTTLS",
+		},
+		{
+			name:    "smtp rejected recipient",
+			monitor: models.Monitor{Type: "smtp", SMTPTo: "nobody@example.test"},
+			script: func(c *fakeMailConn) {
+				if !smtpGreeting(c) || !c.expect("MAIL FROM:<>") {
+					return
+				}
+				c.send("250 ok")
+				if c.expect("RCPT TO:<nobody@example.test>") {
+					c.send("550 5.1.1 no such user")
+				}
+			},
+			err: "SMTP rcpt_to failed: unexpected SMTP reply: 550 5.1.1 no such user",
+		},
+		{
+			name:    "smtp refused banner",
+			monitor: models.Monitor{Type: "smtp"},
+			script:  func(c *fakeMailConn) { c.send("554 go away") },
+			err:     "SMTP banner failed: unexpected SMTP reply: 554 go away",
+		},
+		{
+			name:    "smtp malformed reply",
+			monitor: models.Monitor{Type: "smtp"},
+			script:  func(c *fakeMailConn) { c.send("hi") },
+			err:     `SMTP banner failed: malformed SMTP reply: "hi"`,
+		},
+		{
+			name:    "overlong line",
+			monitor: models.Monitor{Type: "smtp"},
+			script:  func(c *fakeMailConn) { c.send("220 " + strings.Repeat("x", maxLineLength)) },
+			err:     "SMTP banner failed: line longer than 8192 bytes",
+		},
+		{
+			name:    "imap starttls and login",
+			monitor: models.Monitor{Type: "imap", TLSMode: models.TLSModeStartTLS, AuthUsername: "me", AuthPassword: `se"cret`},
+			script: func(c *fakeMailConn) {
+				c.send("* OK IMAP4rev1 ready")
+				if !c.expect("a001 CAPABILITY") {
+					return
+				}
+				c.send("* CAPABILITY IMAP4rev1 STARTTLS LOGINDISABLED", "a001 OK done")
+				if !c.expect("a002 STARTTLS") {
+					return
+				}
+				c.send("a002 OK begin TLS")
+				if !c.upgrade() || !c.expect("a003 CAPABILITY") {
+					return
+				}
+				c.send("* CAPABILITY IMAP4rev1 AUTH=PLAIN", "a003 OK done")
+				if !c.expect(`a004 LOGIN "me" "se\"cret"`) {
+					return
+				}
+				c.send("a004 OK logged in")
+				if c.expect("a005 LOGOUT") {
+					c.send("* BYE", "a005 OK bye")
+				}
+			},
+			message: "IMAP ready: OK IMAP4rev1 ready",
+			steps:   "connect banner capability starttls tls capability login",
+		},
+		{
+			name:    "imap refuses plaintext login",
+			monitor: models.Monitor{Type: "imap", AuthUsername: "me", AuthPassword: "secret"},
+			script: func(c *fakeMailConn) {
+				c.send("* OK ready")
+				if c.expect("a001 CAPABILITY") {
+					c.send("* CAPABILITY IMAP4rev1", "a001 OK done")
+					c.expectClose()
+				}
+			},
+			err: "IMAP login failed: refusing to send credentials without TLS",
+		},
+		{
+			name:    "imap rejected login",
+			monitor: models.Monitor{Type: "imap", TLSMode: models.TLSModeTLS, AuthUsername: "me", AuthPassword: "wrong"},
+			script: func(c *fakeMailConn) {
+				if !c.upgrade() {
+					return
+				}
+				c.send("* OK ready")
+				if c.expect("a001 CAPABILITY") {
+					c.send("* CAPABILITY IMAP4rev1", "a001 OK done")
+				}
+				if c.expect(`a002 LOGIN "me" "wrong"`) {
+					c.send("a002 NO [AUTHENTICATIONFAILED] invalid credentials")
+				}
+			},
+			err: "IMAP login failed: IMAP command failed: NO [AUTHENTICATIONFAILED] invalid credentials",
+		},
+		{
+			name:    "imap bad greeting",
+			monitor: models.Monitor{Type: "imap"},
+			script:  func(c *fakeMailConn) { c.send("* BYE overloaded") },
+			err:     "IMAP banner failed: unexpected IMAP greeting: * BYE overloaded",
+		},
+		{
+			name:    "pop3 stls and login",
+			monitor: models.Monitor{Type: "pop3", TLSMode: models.TLSModeStartTLS, AuthUsername: "me", AuthPassword: "secret"},
+			script: func(c *fakeMailConn) {
+				c.send("+OK POP3 ready")
+				if !c.expect("CAPA") {
+					return
+				}
+				c.send("+OK capabilities", "STLS", "USER", ".")
+				if !c.expect("STLS") {
+					return
+				}
+				c.send("+OK begin TLS")
+				if !c.upgrade() || !c.expect("CAPA") {
+					return
+				}
+				// CAPA is optional, so -ERR is not a failure
+				c.send("-ERR unknown command")
+				for _, step := range [][2]string{{"USER me", "+OK"}, {"PASS secret", "+OK logged in"}, {"QUIT", "+OK bye"}} {
+					if !c.expect(step[0]) {
+						return
+					}
+					c.send(step[1])
+				}
+			},
+			message: "POP3 ready: POP3 ready",
+			steps:   "connect banner capa starttls tls capa login",
+		},
+		{
+			name:    "pop3 refuses plaintext login",
+			monitor: models.Monitor{Type: "pop3", AuthUsername: "me", AuthPassword: "secret"},
+			script: func(c *fakeMailConn) {
+				c.send("+OK ready")
+				if c.expect("CAPA") {
+					c.send("+OK", "USER", ".")
+					c.expectClose()
+				}
+			},
+			err: "POP3 login failed: refusing to send credentials without TLS",
+		},
+		{
+			name:    "pop3 rejected password",
+			monitor: models.Monitor{Type: "pop3", TLSMode: models.TLSModeTLS, AuthUsername: "me", AuthPassword: "wrong"},
+			script: func(c *fakeMailConn) {
+				if !c.upgrade() {
+					return
+				}
+				c.send("+OK ready")
+				for _, step := range [][2]string{{"CAPA", "+OK\r\n."}, {"USER me", "+OK"}, {"PASS wrong", "-ERR [AUTH] invalid password"}} {
+					if !c.expect(step[0]) {
+						return
+					}
+					c.send(step[1])
+				}
+			},
+			err: "POP3 login failed: unexpected POP3 reply: -ERR [AUTH] invalid password",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := tt.monitor
+			monitor.URL = startMailServer(t, cert, tt.script)
+			monitor.Timeout = 5
+			monitor.TLSCABundle = certPEM(cert.Leaf)
+			mc := &MonitorChecker{monitor: monitor, manager: &Manager{}}
+
+			var check models.MonitorCheck
+			err := mc.checker().Check(context.Background(), &check)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if check.Status != "up" || check.Message != tt.message {
+				t.Errorf("got %s %q, want up %q", check.Status, check.Message, tt.message)
+			}
+			var steps []string
+			for _, step := range check.Steps {
+				steps = append(steps, step.Name)
+			}
+			if got := strings.Join(steps, " "); got != tt.steps {
+				t.Errorf("steps %q, want %q", got, tt.steps)
+			}
+		})
+	}
+}
@@ -1,47 +1,93 @@
 package monitoring
 
 import (
+	"context"
+	"crypto/x509"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
-	"net"
-	"net/http"
-	"net/url"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"uptime-monitor/internal/config"
 	"uptime-monitor/internal/models"
 	"uptime-monitor/internal/notifications"
+	"uptime-monitor/internal/websocket"
 
-	"github.com/go-ping/ping"
 	"github.com/jmoiron/sqlx"
 	"github.com/robfig/cron/v3"
 )
 
+// defaultRetryInterval is used for monitors without a retry interval
+const defaultRetryInterval = 20 * time.Second
+
+// defaultWorkers is the worker pool size when the config does not set one
+const defaultWorkers = 50
+
 type Manager struct {
 	db                    *sqlx.DB
 	cron                  *cron.Cron
 	checkers              map[int]*MonitorChecker
 	mu                    sync.RWMutex
 	shoutrrrManager       *notifications.ShoutrrrManager
-	slowResponseThreshold int // in milliseconds
+	hub                   *websocket.Hub
+	slowResponseThreshold int            // in milliseconds
+	sslExpiryDays         []int          // certificate expiry notification thresholds
+	rootCAs               *x509.CertPool // nil uses the system roots
+	execEnabled           bool           // whether exec monitors may run commands
+	execPath              string         // PATH for exec monitor commands
+	proxyURL              string         // default proxy for HTTP checks
+	workers               chan struct{}  // one slot per worker running a check
+	counters              schedulerCounters
+	ctx                   context.Context // passed to checks; cancelled to abort them
+	cancel                context.CancelFunc
+	stopping              chan struct{}  // closed when Stop is called
+	alerts                sync.WaitGroup // notifications being sent
+	manual                sync.WaitGroup // checks started with CheckNow
 }
 
 type MonitorChecker struct {
-	monitor models.Monitor
-	cronID  cron.EntryID
-	manager *Manager
+	monitor   models.Monitor
+	cronID    cron.EntryID
+	manager   *Manager
+	startedAt time.Time
+	busy      atomic.Bool     // a check is queued or running
+	ctx       context.Context // cancelled when the monitor is removed
+	cancel    context.CancelFunc
+	dryRun    bool // a test run, which must not read or write stored state
 }
 
-func NewManager(db *sqlx.DB) *Manager {
+func NewManager(db *sqlx.DB, hub *websocket.Hub, cfg config.MonitorConfig) *Manager {
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Manager{
 		db:                    db,
 		cron:                  cron.New(),
 		checkers:              make(map[int]*MonitorChecker),
 		shoutrrrManager:       notifications.NewShoutrrrManager(db),
+		hub:                   hub,
 		slowResponseThreshold: 5000, // 5 seconds default
+		sslExpiryDays:         cfg.SSLExpiryDays,
+		execEnabled:           cfg.ExecEnabled,
+		execPath:              cfg.ExecPath,
+		proxyURL:              cfg.ProxyURL,
+		workers:               make(chan struct{}, workers),
+		ctx:                   ctx,
+		cancel:                cancel,
+		stopping:              make(chan struct{}),
 	}
 }
 
+// ExecEnabled reports whether exec monitors are allowed by the server config
+func (m *Manager) ExecEnabled() bool {
+	return m.execEnabled
+}
+
 func (m *Manager) Start() error {
 	// Load existing monitors from database
 	if err := m.loadMonitors(); err != nil {
@@ -53,11 +99,71 @@ func (m *Manager) Start() error {
 	return nil
 }
 
-func (m *Manager) Stop() {
-	m.cron.Stop()
+// Stop stops scheduling checks and waits for the running ones to finish.
+// Checks still running when ctx is done are cancelled and not saved. Queued
+// notifications are then sent, again only until ctx is done.
+func (m *Manager) Stop(ctx context.Context) {
+	// CheckNow registers its checks under the lock, so none start after this
+	m.mu.Lock()
+	close(m.stopping)
+	m.mu.Unlock()
+
+	running := m.cron.Stop()
+	finished := make(chan struct{})
+	go func() {
+		<-running.Done()
+		m.manual.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-ctx.Done():
+		log.Println("Cancelling running checks")
+		m.cancel()
+		<-finished
+	}
+	m.cancel()
+
+	if !waitFor(ctx, &m.alerts) {
+		log.Println("Gave up waiting for notifications to be sent")
+	}
+
 	log.Println("Monitor manager stopped")
 }
 
+// waitFor waits for wg until ctx is done and reports whether it finished
+func waitFor(ctx context.Context, wg *sync.WaitGroup) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		select {
+		case <-done:
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// sendAlert sends a notification in the background; Stop waits for it
+func (m *Manager) sendAlert(monitor models.Monitor, check models.MonitorCheck, event models.NotificationEvent, previousStatus string) {
+	m.alerts.Add(1)
+	go func() {
+		defer m.alerts.Done()
+		if err := m.shoutrrrManager.SendMonitorAlert(monitor, check, event, previousStatus); err != nil {
+			log.Printf("Failed to send notification for monitor %d: %v", monitor.ID, err)
+		}
+	}()
+}
+
 func (m *Manager) loadMonitors() error {
 	monitors := []models.Monitor{}
 	query := "SELECT * FROM monitors WHERE active = ?"
@@ -79,25 +185,30 @@ func (m *Manager) AddMonitor(monitor models.Monitor) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// Remove existing checker if any
+	// Remove existing checker if any, aborting its running check
 	if checker, exists := m.checkers[monitor.ID]; exists {
 		m.cron.Remove(checker.cronID)
+		checker.cancel()
 	}
 
 	// Create new checker
+	ctx, cancel := context.WithCancel(m.ctx)
 	checker := &MonitorChecker{
-		monitor: monitor,
-		manager: m,
+		monitor:   monitor,
+		manager:   m,
+		startedAt: time.Now(),
+		ctx:       ctx,
+		cancel:    cancel,
 	}
 
-	// Schedule checks
-	spec := fmt.Sprintf("@every %ds", monitor.Interval)
-	cronID, err := m.cron.AddFunc(spec, checker.check)
-	if err != nil {
-		return fmt.Errorf("failed to schedule monitor: %v", err)
+	if monitor.Interval <= 0 {
+		return fmt.Errorf("failed to schedule monitor: invalid interval %d", monitor.Interval)
 	}
 
-	checker.cronID = cronID
+	// Schedule checks at the monitor's offset within its interval; due
+	// checks wait for a free worker
+	schedule := newJitterSchedule(monitor.ID, time.Duration(monitor.Interval)*time.Second)
+	checker.cronID = m.cron.Schedule(schedule, cron.FuncJob(func() { m.dispatch(checker) }))
 	m.checkers[monitor.ID] = checker
 
 	log.Printf("Added monitor: %s (ID: %d)", monitor.Name, monitor.ID)
@@ -110,187 +221,270 @@ func (m *Manager) RemoveMonitor(monitorID int) {
 
 	if checker, exists := m.checkers[monitorID]; exists {
 		m.cron.Remove(checker.cronID)
+		checker.cancel()
 		delete(m.checkers, monitorID)
 		log.Printf("Removed monitor ID: %d", monitorID)
 	}
 }
 
-func (mc *MonitorChecker) check() {
-	start := time.Now()
-	check := models.MonitorCheck{
-		MonitorID: mc.monitor.ID,
-		CheckedAt: start,
-	}
-
-	var err error
-	switch mc.monitor.Type {
-	case "http", "https":
-		err = mc.checkHTTP(&check)
-	case "tcp":
-		err = mc.checkTCP(&check)
-	case "ping":
-		err = mc.checkPing(&check)
-	default:
-		check.Status = "unknown"
-		check.Message = "Unknown monitor type"
-	}
-
-	if err != nil {
-		check.Status = "down"
-		check.Message = err.Error()
-	} else if check.Status == "" {
-		check.Status = "up"
+func (mc *MonitorChecker) check(ctx context.Context) {
+	// Push monitors are fed by heartbeats; the schedule only watches for
+	// missing ones
+	if mc.monitor.Type == "push" {
+		mc.checkPush()
+		return
 	}
 
-	check.ResponseTime = int(time.Since(start).Milliseconds())
-
-	// Save check result
-	if err := mc.saveCheck(check); err != nil {
+	if _, err := mc.checkAndSave(ctx); err != nil && ctx.Err() == nil && !errors.Is(err, ErrStopping) {
 		log.Printf("Failed to save check for monitor %d: %v", mc.monitor.ID, err)
 	}
 }
 
-func (mc *MonitorChecker) checkHTTP(check *models.MonitorCheck) error {
-	client := &http.Client{
-		Timeout: time.Duration(mc.monitor.Timeout) * time.Second,
-	}
-
-	resp, err := client.Get(mc.monitor.URL)
+// checkAndSave runs the monitor's check, confirms a change of state with
+// re-checks and saves the result, which it returns. Checks interrupted by
+// a shutdown or by the monitor's removal are not results and are not
+// saved; their error is returned instead.
+func (mc *MonitorChecker) checkAndSave(ctx context.Context) (models.MonitorCheck, error) {
+	check, err := mc.attempt(ctx)
 	if err != nil {
-		return err
+		return check, err
 	}
-	defer resp.Body.Close()
 
-	check.StatusCode = resp.StatusCode
+	// A change of state is only recorded once it has been confirmed by
+	// re-checks; the attempts in between are saved as "pending"
+	confirmed := mc.lastConfirmedStatus()
+	for attempt := 1; attempt <= mc.monitor.MaxRetries && needsConfirmation(check.Status, confirmed); attempt++ {
+		pending := check
+		pending.Status = "pending"
+		pending.Message = fmt.Sprintf("Retry %d/%d: %s", attempt, mc.monitor.MaxRetries, check.Message)
+		if err := mc.saveCheck(&pending); err != nil {
+			log.Printf("Failed to save check for monitor %d: %v", mc.monitor.ID, err)
+		}
 
-	if resp.StatusCode >= 200 && resp.StatusCode < 400 {
-		check.Status = "up"
-		check.Message = "OK"
-	} else {
-		check.Status = "down"
-		check.Message = fmt.Sprintf("HTTP %d", resp.StatusCode)
+		select {
+		case <-time.After(mc.retryInterval()):
+		case <-ctx.Done():
+			return check, ctx.Err()
+		case <-mc.manager.stopping:
+			return check, ErrStopping
+		}
+		if check, err = mc.attempt(ctx); err != nil {
+			return check, err
+		}
 	}
 
-	return nil
+	err = mc.saveCheck(&check)
+	return check, err
 }
 
-func (mc *MonitorChecker) checkTCP(check *models.MonitorCheck) error {
-	var address string
-
-	// Handle different URL formats for TCP
-	if strings.HasPrefix(mc.monitor.URL, "tcp://") {
-		u, err := url.Parse(mc.monitor.URL)
-		if err != nil {
-			return fmt.Errorf("invalid TCP URL: %v", err)
-		}
-		address = u.Host
-	} else {
-		// Handle direct host:port format
-		address = mc.monitor.URL
+// attempt runs one check attempt on a worker from the pool, returning an
+// error if it could not start or was interrupted
+func (mc *MonitorChecker) attempt(ctx context.Context) (models.MonitorCheck, error) {
+	release, err := mc.manager.acquireWorker(ctx)
+	if err != nil {
+		return models.MonitorCheck{}, err
 	}
+	defer release()
+
+	check := mc.runCheck(ctx)
+	return check, ctx.Err()
+}
 
-	if address == "" {
-		return fmt.Errorf("no host:port specified")
+// runCheck performs a single check attempt without saving it
+func (mc *MonitorChecker) runCheck(ctx context.Context) models.MonitorCheck {
+	start := time.Now()
+	check := models.MonitorCheck{
+		MonitorID: mc.monitor.ID,
+		CheckedAt: start,
 	}
 
-	// Validate address format (should be host:port)
-	if !strings.Contains(address, ":") {
-		return fmt.Errorf("TCP check requires host:port format, got: %s", address)
+	var err error
+	if checker := mc.checker(); checker != nil {
+		check, err = mc.runChecker(ctx, checker, check)
+	} else {
+		check.Status = "unknown"
+		check.Message = "Unknown monitor type"
 	}
 
-	// Try to connect
-	timeout := time.Duration(mc.monitor.Timeout) * time.Second
-	conn, err := net.DialTimeout("tcp", address, timeout)
 	if err != nil {
-		return fmt.Errorf("TCP connection failed to %s: %v", address, err)
+		check.Status = "down"
+		check.Message = err.Error()
+	} else if check.Status == "" {
+		check.Status = "up"
 	}
-	conn.Close()
 
-	check.Status = "up"
-	check.Message = fmt.Sprintf("TCP connection successful to %s", address)
-	return nil
+	check.ResponseTime = int(time.Since(start).Milliseconds())
+	return check
 }
 
-func (mc *MonitorChecker) checkPing(check *models.MonitorCheck) error {
-	// Parse URL to get hostname
-	u, err := url.Parse(mc.monitor.URL)
-	if err != nil {
-		return fmt.Errorf("invalid URL: %v", err)
+// needsConfirmation reports whether a result differs from the last confirmed
+// status in a way that must be re-checked. The very first "up" is trusted.
+func needsConfirmation(status, confirmed string) bool {
+	status, confirmed = alertStatus(status), alertStatus(confirmed)
+	if status != "up" && status != "down" {
+		return false
 	}
-
-	host := u.Host
-	if host == "" {
-		host = u.Path // For ping://hostname format
+	if confirmed == "unknown" && status == "up" {
+		return false
 	}
+	return status != confirmed
+}
 
-	pinger, err := ping.NewPinger(host)
-	if err != nil {
-		return err
-	}
-	pinger.SetPrivileged(false) // Use unprivileged mode for FreeBSD compatibility
-	pinger.Count = 3
-	pinger.Timeout = time.Duration(mc.monitor.Timeout) * time.Second
-
-	err = pinger.Run()
-	if err != nil {
-		return err
+// alertStatus maps a status to the one it alerts as, the way
+// notifications.DetermineEvent compares them: degraded as "up" and timeout
+// as "down"
+func alertStatus(status string) string {
+	switch status {
+	case "degraded":
+		return "up"
+	case "timeout":
+		return "down"
 	}
+	return status
+}
 
-	stats := pinger.Statistics()
-	if stats.PacketsRecv == 0 {
-		return fmt.Errorf("no packets received")
+func (mc *MonitorChecker) retryInterval() time.Duration {
+	if mc.monitor.RetryInterval <= 0 {
+		return defaultRetryInterval
 	}
-
-	check.Status = "up"
-	check.Message = fmt.Sprintf("Ping successful, avg RTT: %v", stats.AvgRtt)
-	check.ResponseTime = int(stats.AvgRtt.Milliseconds())
-	return nil
+	return time.Duration(mc.monitor.RetryInterval) * time.Second
 }
 
-func (mc *MonitorChecker) saveCheck(check models.MonitorCheck) error {
-	// Get previous status for notification comparison
-	var previousStatus string
-	err := mc.manager.db.Get(&previousStatus, `
+// lastConfirmedStatus returns the latest status that was not a retry attempt
+func (mc *MonitorChecker) lastConfirmedStatus() string {
+	var status string
+	err := mc.manager.db.Get(&status, `
 		SELECT status FROM monitor_checks 
-		WHERE monitor_id = ? 
+		WHERE monitor_id = ? AND status != 'pending'
 		ORDER BY checked_at DESC 
 		LIMIT 1
-	`, check.MonitorID)
+	`, mc.monitor.ID)
 
 	if err != nil {
 		// No previous checks, assume unknown
-		previousStatus = "unknown"
+		return "unknown"
 	}
+	return status
+}
+
+// saveCheck stores check, setting its ID, then broadcasts it and sends the
+// notifications it calls for
+func (mc *MonitorChecker) saveCheck(check *models.MonitorCheck) error {
+	// Get previous status for notification comparison
+	previousStatus := mc.lastConfirmedStatus()
+	previousCertDays := mc.lastCertDaysRemaining()
 
 	query := `
-		INSERT INTO monitor_checks (monitor_id, status, response_time, status_code, message, checked_at)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO monitor_checks (monitor_id, status, response_time, status_code, message, checked_at,
+			cert_expires_at, cert_issuer, cert_sans, cert_days_remaining,
+			ping_min, ping_avg, ping_max, ping_stddev, packet_loss, metrics, steps,
+			dns_time, connect_time, tls_time, ttfb, transfer_time, content_hash, content_diff, families)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err = mc.manager.db.Exec(query,
+	args := []interface{}{
 		check.MonitorID,
 		check.Status,
 		check.ResponseTime,
 		check.StatusCode,
 		check.Message,
 		check.CheckedAt,
-	)
+		check.CertExpiresAt,
+		check.CertIssuer,
+		check.CertSANs,
+		check.CertDaysRemaining,
+		check.PingMin,
+		check.PingAvg,
+		check.PingMax,
+		check.PingStdDev,
+		check.PacketLoss,
+		check.Metrics,
+		check.Steps,
+		check.DNSTime,
+		check.ConnectTime,
+		check.TLSTime,
+		check.TTFB,
+		check.TransferTime,
+		check.ContentHash,
+		check.ContentDiff,
+		check.Families,
+	}
 
+	tx, err := mc.manager.db.Beginx()
+	if err != nil {
+		return err
+	}
+	// lib/pq has no LastInsertId, so Postgres hands the id back itself
+	if mc.manager.db.DriverName() == "postgres" {
+		err = tx.QueryRowx(tx.Rebind(query+" RETURNING id"), args...).Scan(&check.ID)
+	} else {
+		var result sql.Result
+		if result, err = tx.Exec(query, args...); err == nil {
+			var id int64
+			if id, err = result.LastInsertId(); err == nil {
+				check.ID = int(id)
+			}
+		}
+	}
 	if err != nil {
+		tx.Rollback()
 		return err
 	}
 
+	// Content mode baselines move with the check that saw them
+	if check.ContentSnapshot != nil {
+		_, err = tx.Exec("UPDATE monitors SET content_hash = ?, content_snapshot = ? WHERE id = ?",
+			check.ContentHash, *check.ContentSnapshot, check.MonitorID)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to store content: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// Push the result to connected dashboards
+	mc.manager.publish(websocket.EventCheck, check.MonitorID, mc.monitor.TagList(), *check)
+	// Retry attempts are not a status of their own
+	if check.Status != "pending" && check.Status != previousStatus {
+		mc.manager.publish(websocket.EventStatusChange, check.MonitorID, mc.monitor.TagList(), map[string]interface{}{
+			"previous_status": previousStatus,
+			"status":          check.Status,
+			"check":           *check,
+		})
+	}
+
 	// Determine which event to send notification for
 	event := notifications.DetermineEvent(check.Status, previousStatus, check.ResponseTime, mc.manager.slowResponseThreshold)
 
 	// Send notifications if there's a relevant event
 	if event != "" {
-		go func() {
-			if err := mc.manager.shoutrrrManager.SendMonitorAlert(mc.monitor, check, event, previousStatus); err != nil {
-				log.Printf("Failed to send notification for monitor %d: %v", mc.monitor.ID, err)
-			}
-		}()
+		mc.manager.sendAlert(mc.monitor, *check, event, previousStatus)
+	}
+
+	// Certificate expiry is tracked independently of the up/down state
+	if crossesExpiryThreshold(check.CertDaysRemaining, previousCertDays, mc.manager.sslExpiryDays) {
+		mc.manager.sendAlert(mc.monitor, *check, models.EventSSLExpiringSoon, previousStatus)
+	}
+
+	// Content changes are reported whatever the status
+	if check.ContentDiff != "" {
+		mc.manager.sendAlert(mc.monitor, *check, models.EventContentChanged, previousStatus)
 	}
 
 	return nil
 }
+
+// publish sends an event to WebSocket clients if a hub is attached
+func (m *Manager) publish(eventType string, monitorID int, tags []string, data interface{}) {
+	if m.hub == nil {
+		return
+	}
+	m.hub.Publish(websocket.Event{
+		Type:      eventType,
+		MonitorID: monitorID,
+		Data:      data,
+		Tags:      tags,
+	})
+}
@@ -0,0 +1,269 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"uptime-monitor/internal/auth"
+	"uptime-monitor/internal/config"
+	"uptime-monitor/internal/database"
+	"uptime-monitor/internal/models"
+	"uptime-monitor/internal/websocket"
+
+	gorilla "github.com/gorilla/websocket"
+	"github.com/jmoiron/sqlx"
+)
+
+// newTestManager returns a manager on a fresh SQLite database, stopped when
+// the test ends
+func newTestManager(t *testing.T, cfg config.MonitorConfig) (*Manager, *sqlx.DB) {
+	t.Helper()
+	return newTestManagerWithHub(t, cfg, nil)
+}
+
+// newTestManagerWithHub is newTestManager publishing to hub
+func newTestManagerWithHub(t *testing.T, cfg config.MonitorConfig, hub *websocket.Hub) (*Manager, *sqlx.DB) {
+	t.Helper()
+	db, err := database.Initialize(config.DatabaseConfig{Type: "sqlite", Database: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewManager(db, hub, cfg)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		m.Stop(ctx)
+		db.Close()
+	})
+	return m, db
+}
+
+// insertMonitor stores monitor and returns it with its ID
+func insertMonitor(t *testing.T, db *sqlx.DB, monitor models.Monitor) models.Monitor {
+	t.Helper()
+	result, err := db.Exec("INSERT INTO monitors (name, url, type) VALUES (?, ?, ?)", monitor.Name, monitor.URL, monitor.Type)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := result.LastInsertId()
+	monitor.ID = int(id)
+	return monitor
+}
+
+// closedPort returns a local address nothing listens on
+func closedPort(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return addr
+}
+
+func TestRetryWaitReleasesWorker(t *testing.T) {
+	m, db := newTestManager(t, config.MonitorConfig{Workers: 1})
+	monitor := insertMonitor(t, db, models.Monitor{Name: "down", URL: closedPort(t), Type: "tcp"})
+	monitor.Timeout = 1
+	monitor.MaxRetries = 1
+	monitor.RetryInterval = 2
+
+	// The first result differs from the unknown status, so it waits to be
+	// confirmed
+	mc := &MonitorChecker{monitor: monitor, manager: m, ctx: m.ctx}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.dispatch(mc)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		var pending int
+		if err := db.Get(&pending, "SELECT COUNT(*) FROM monitor_checks WHERE status = 'pending'"); err != nil {
+			t.Fatal(err)
+		}
+		if pending == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no pending check saved")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	release, err := m.acquireWorker(ctx)
+	if err != nil {
+		t.Fatalf("worker held during the retry wait: %v", err)
+	}
+	release()
+
+	<-done
+	var status string
+	if err := db.Get(&status, "SELECT status FROM monitor_checks ORDER BY id DESC LIMIT 1"); err != nil {
+		t.Fatal(err)
+	}
+	if status != "down" {
+		t.Errorf("confirmed status %q, want down", status)
+	}
+}
+
+func TestNeedsConfirmation(t *testing.T) {
+	tests := []struct {
+		status, confirmed string
+		want              bool
+	}{
+		{"up", "unknown", false},
+		{"down", "unknown", true},
+		{"down", "up", true},
+		{"timeout", "up", true},
+		{"timeout", "down", false},
+		{"down", "timeout", false},
+		{"up", "down", true},
+		{"up", "timeout", true},
+		{"degraded", "down", true},
+		{"degraded", "up", false},
+		{"up", "degraded", false},
+		{"down", "degraded", true},
+		{"degraded", "unknown", false},
+		{"unknown", "up", false},
+	}
+	for _, tt := range tests {
+		if got := needsConfirmation(tt.status, tt.confirmed); got != tt.want {
+			t.Errorf("needsConfirmation(%q, %q) = %v, want %v", tt.status, tt.confirmed, got, tt.want)
+		}
+	}
+}
+
+// watchEvents connects a WebSocket client subscribed to every monitor
+func watchEvents(t *testing.T, hub *websocket.Hub) *gorilla.Conn {
+	t.Helper()
+	authService := auth.NewService("test-secret")
+	token, err := authService.GenerateToken(models.User{ID: 1, Username: "admin", Role: "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(hub.HandleWebSocket(authService))
+	t.Cleanup(server.Close)
+
+	conn, _, err := gorilla.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"?token="+token, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	// The reply shows the client is registered with the hub
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := conn.WriteJSON(websocket.ClientMessage{Action: websocket.ActionSubscribe, All: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := conn.ReadMessage(); err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func TestStatusChangeOnlyForConfirmedStatus(t *testing.T) {
+	hub := websocket.NewHub()
+	go hub.Run()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		hub.Close(ctx)
+	})
+	m, db := newTestManagerWithHub(t, config.MonitorConfig{}, hub)
+	conn := watchEvents(t, hub)
+
+	monitor := insertMonitor(t, db, models.Monitor{Name: "api", URL: "localhost:1", Type: "tcp"})
+	mc := &MonitorChecker{monitor: monitor, manager: m, ctx: m.ctx}
+	for i, status := range []string{"up", "pending", "pending", "down", "pending", "down", "degraded", "up"} {
+		check := models.MonitorCheck{MonitorID: monitor.ID, Status: status, CheckedAt: time.Now().Add(time.Duration(i) * time.Second)}
+		if err := mc.saveCheck(&check); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m.publish("done", monitor.ID, nil, nil)
+
+	var changes []string
+	for {
+		var event struct {
+			Type string `json:"type"`
+			Data struct {
+				Previous string `json:"previous_status"`
+				Status   string `json:"status"`
+			} `json:"data"`
+		}
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(message, &event); err != nil {
+			t.Fatal(err)
+		}
+		if event.Type == "done" {
+			break
+		}
+		if event.Type == websocket.EventStatusChange {
+			changes = append(changes, event.Data.Previous+">"+event.Data.Status)
+		}
+	}
+
+	want := strings.Join([]string{"unknown>up", "up>down", "down>degraded", "degraded>up"}, " ")
+	if got := strings.Join(changes, " "); got != want {
+		t.Errorf("status changes %q, want %q", got, want)
+	}
+}
+
+func TestSaveCheckSetsID(t *testing.T) {
+	m, db := newTestManager(t, config.MonitorConfig{})
+	monitor := insertMonitor(t, db, models.Monitor{Name: "api", URL: "localhost:1", Type: "tcp"})
+	mc := &MonitorChecker{monitor: monitor, manager: m, ctx: m.ctx}
+
+	for i := 0; i < 2; i++ {
+		check := models.MonitorCheck{MonitorID: monitor.ID, Status: "up", CheckedAt: time.Now()}
+		if err := mc.saveCheck(&check); err != nil {
+			t.Fatal(err)
+		}
+		var stored int
+		if err := db.Get(&stored, "SELECT MAX(id) FROM monitor_checks WHERE monitor_id = ?", monitor.ID); err != nil {
+			t.Fatal(err)
+		}
+		if check.ID == 0 || check.ID != stored {
+			t.Errorf("check %d got id %d, want %d", i, check.ID, stored)
+		}
+	}
+}
+
+func TestTestMonitorWaitsForWorker(t *testing.T) {
+	m, _ := newTestManager(t, config.MonitorConfig{Workers: 1})
+	release, err := m.acquireWorker(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	monitor := models.Monitor{Name: "down", URL: closedPort(t), Type: "tcp", Timeout: 1}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := m.TestMonitor(ctx, monitor); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("test with every worker busy returned %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	release()
+	check, err := m.TestMonitor(context.Background(), monitor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check.Status != "down" {
+		t.Errorf("status %q, want down", check.Status)
+	}
+}
@@ -0,0 +1,93 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"uptime-monitor/internal/models"
+)
+
+var (
+	// ErrCheckRunning is returned by CheckNow while the monitor's check is
+	// already running
+	ErrCheckRunning = errors.New("a check is already running for this monitor")
+	// ErrStopping is returned once the manager is shutting down
+	ErrStopping = errors.New("monitor manager is stopping")
+	// ErrPushMonitor is returned for push monitors, which are checked by
+	// their heartbeats
+	ErrPushMonitor = errors.New("push monitors are checked by their heartbeats")
+)
+
+// CheckNow runs one attempt of a monitor's check straight away, on a
+// worker from the pool, and saves, broadcasts and alerts on the result like
+// a scheduled check. A result that would change the monitor's state is
+// saved as "pending", leaving the schedule's re-checks to confirm it.
+// Paused monitors can be checked too. The check is abandoned when ctx is
+// done and it returns the saved check.
+func (m *Manager) CheckNow(ctx context.Context, monitor models.Monitor) (models.MonitorCheck, error) {
+	if monitor.Type == "push" {
+		return models.MonitorCheck{}, ErrPushMonitor
+	}
+
+	m.mu.RLock()
+	select {
+	case <-m.stopping:
+		m.mu.RUnlock()
+		return models.MonitorCheck{}, ErrStopping
+	default:
+	}
+	m.manual.Add(1)
+	checker, scheduled := m.checkers[monitor.ID]
+	m.mu.RUnlock()
+	defer m.manual.Done()
+
+	if !scheduled {
+		checkerCtx, cancel := context.WithCancel(m.ctx)
+		defer cancel()
+		checker = &MonitorChecker{monitor: monitor, manager: m, startedAt: time.Now(), ctx: checkerCtx, cancel: cancel}
+	}
+
+	// Sharing the flag with the schedule keeps the two from overlapping
+	if !checker.busy.CompareAndSwap(false, true) {
+		return models.MonitorCheck{}, ErrCheckRunning
+	}
+	defer checker.busy.Store(false)
+
+	// Stop with the request as well as with the monitor
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(checker.ctx, cancel)
+	defer stop()
+
+	check, err := checker.attempt(ctx)
+	if err != nil {
+		return check, err
+	}
+
+	if checker.monitor.MaxRetries > 0 && needsConfirmation(check.Status, checker.lastConfirmedStatus()) {
+		check.Status = "pending"
+		check.Message = fmt.Sprintf("Manual check, awaiting confirmation: %s", check.Message)
+	}
+	err = checker.saveCheck(&check)
+	return check, err
+}
+
+// TestMonitor runs one attempt of a monitor's check on a worker from the
+// pool and returns the result without storing anything or sending
+// notifications. The monitor need not
+// be saved; content mode reports the content without comparing it.
+func (m *Manager) TestMonitor(ctx context.Context, monitor models.Monitor) (models.MonitorCheck, error) {
+	if monitor.Type == "push" {
+		return models.MonitorCheck{}, ErrPushMonitor
+	}
+
+	// Abort with the request or when the manager stops
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(m.ctx, cancel)
+	defer stop()
+
+	checker := &MonitorChecker{monitor: monitor, manager: m, startedAt: time.Now(), ctx: ctx, cancel: cancel, dryRun: true}
+	return checker.attempt(ctx)
+}
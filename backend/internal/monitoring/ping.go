@@ -0,0 +1,113 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"uptime-monitor/internal/models"
+
+	"github.com/go-ping/ping"
+)
+
+// pingNetworks maps address families to the resolver network for go-ping
+var pingNetworks = map[string]string{
+	models.AddressFamilyAny:  "ip",
+	models.AddressFamilyIPv4: "ip4",
+	models.AddressFamilyIPv6: "ip6",
+}
+
+func (mc *MonitorChecker) checkPing(ctx context.Context, check *models.MonitorCheck) error {
+	// Parse URL to get hostname
+	u, err := url.Parse(mc.monitor.URL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %v", err)
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		host = u.Path // For ping://hostname format
+	}
+
+	pinger := ping.New(host)
+	pinger.SetNetwork(pingNetworks[mc.monitor.AddressFamily])
+	if err := pinger.Resolve(); err != nil {
+		return err
+	}
+	// Unprivileged (UDP) mode works without root, which FreeBSD requires for raw ICMP
+	pinger.SetPrivileged(mc.monitor.PingPrivileged)
+	pinger.Count = mc.monitor.PingCount
+	if pinger.Count <= 0 {
+		pinger.Count = 3
+	}
+	pinger.Timeout = time.Duration(mc.monitor.Timeout) * time.Second
+
+	stop := context.AfterFunc(ctx, pinger.Stop)
+	err = pinger.Run()
+	stop()
+	if err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	stats := pinger.Statistics()
+	recordPingStats(check, stats)
+	if stats.PacketsRecv == 0 {
+		return fmt.Errorf("no packets received from %s", stats.IPAddr)
+	}
+
+	check.ResponseTime = int(stats.AvgRtt.Milliseconds())
+	summary := fmt.Sprintf("%d/%d replies from %s, loss %.1f%%, rtt min/avg/max/stddev %s/%s/%s/%s",
+		stats.PacketsRecv, stats.PacketsSent, stats.IPAddr, stats.PacketLoss,
+		stats.MinRtt.Round(time.Microsecond), stats.AvgRtt.Round(time.Microsecond),
+		stats.MaxRtt.Round(time.Microsecond), stats.StdDevRtt.Round(time.Microsecond))
+
+	if violations := mc.pingViolations(stats); len(violations) > 0 {
+		check.Status = "down"
+		check.Message = fmt.Sprintf("%s (%s)", strings.Join(violations, ", "), summary)
+		return nil
+	}
+
+	check.Status = "up"
+	check.Message = "Ping successful: " + summary
+	return nil
+}
+
+// pingViolations lists the monitor's ping thresholds exceeded by stats
+func (mc *MonitorChecker) pingViolations(stats *ping.Statistics) []string {
+	var violations []string
+	if max := mc.monitor.PingMaxLoss; max != nil && stats.PacketLoss > *max {
+		violations = append(violations, fmt.Sprintf("packet loss %.1f%% exceeds %.1f%%", stats.PacketLoss, *max))
+	}
+	if max := time.Duration(mc.monitor.PingMaxRTT) * time.Millisecond; max > 0 && stats.AvgRtt > max {
+		violations = append(violations, fmt.Sprintf("average RTT %s exceeds %s", stats.AvgRtt.Round(time.Microsecond), max))
+	}
+	if max := time.Duration(mc.monitor.PingMaxJitter) * time.Millisecond; max > 0 && stats.StdDevRtt > max {
+		violations = append(violations, fmt.Sprintf("jitter %s exceeds %s", stats.StdDevRtt.Round(time.Microsecond), max))
+	}
+	return violations
+}
+
+// recordPingStats stores the ping statistics on the check. RTTs are only
+// meaningful when at least one reply arrived.
+func recordPingStats(check *models.MonitorCheck, stats *ping.Statistics) {
+	loss := stats.PacketLoss
+	check.PacketLoss = &loss
+	if stats.PacketsRecv == 0 {
+		return
+	}
+
+	check.PingMin = durationMillis(stats.MinRtt)
+	check.PingAvg = durationMillis(stats.AvgRtt)
+	check.PingMax = durationMillis(stats.MaxRtt)
+	check.PingStdDev = durationMillis(stats.StdDevRtt)
+}
+
+// durationMillis converts d to fractional milliseconds
+func durationMillis(d time.Duration) *float64 {
+	ms := float64(d) / float64(time.Millisecond)
+	return &ms
+}
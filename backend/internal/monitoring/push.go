@@ -0,0 +1,71 @@
+package monitoring
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+	"uptime-monitor/internal/models"
+)
+
+// GeneratePushToken returns a random token for a push monitor URL
+func GeneratePushToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// RecordPush saves a heartbeat received for an active push monitor
+func (m *Manager) RecordPush(monitorID int, check models.MonitorCheck) error {
+	m.mu.RLock()
+	checker, exists := m.checkers[monitorID]
+	m.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("monitor %d is not active", monitorID)
+	}
+
+	if _, err := m.db.Exec("UPDATE monitors SET last_push_at = ? WHERE id = ?", check.CheckedAt, monitorID); err != nil {
+		return err
+	}
+
+	return checker.saveCheck(&check)
+}
+
+// checkPush runs on the monitor's interval and records a "down" check when
+// no heartbeat arrived within the interval plus the grace period
+func (mc *MonitorChecker) checkPush() {
+	var lastPush *time.Time
+	if err := mc.manager.db.Get(&lastPush, "SELECT last_push_at FROM monitors WHERE id = ?", mc.monitor.ID); err != nil {
+		log.Printf("Failed to read last heartbeat for monitor %d: %v", mc.monitor.ID, err)
+		return
+	}
+
+	// Monitors that never received a heartbeat count from when they were scheduled
+	since := mc.startedAt
+	if lastPush != nil && lastPush.After(since) {
+		since = *lastPush
+	}
+
+	deadline := time.Duration(mc.monitor.Interval+mc.monitor.PushGracePeriod) * time.Second
+	if time.Since(since) <= deadline {
+		return
+	}
+
+	check := models.MonitorCheck{
+		MonitorID: mc.monitor.ID,
+		Status:    "down",
+		Message:   fmt.Sprintf("No heartbeat received within %s", deadline),
+		CheckedAt: time.Now(),
+	}
+	if lastPush != nil {
+		check.Message += fmt.Sprintf(" (last at %s)", lastPush.Format("2006-01-02 15:04:05 MST"))
+	}
+
+	if err := mc.saveCheck(&check); err != nil {
+		log.Printf("Failed to save check for monitor %d: %v", mc.monitor.ID, err)
+	}
+}
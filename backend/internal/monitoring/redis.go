@@ -0,0 +1,174 @@
+package monitoring
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"uptime-monitor/internal/models"
+)
+
+// Limits on replies read from a Redis server, so a hostile one cannot
+// exhaust memory or the stack
+const (
+	maxRedisReply = 1 << 20 // bulk string and array lengths
+	maxRedisLine  = 64 << 10
+	maxRedisDepth = 16 // nested arrays
+)
+
+// checkRedis authenticates against a Redis server, selects the database
+// from the URL path and runs the monitor's command. The reply is available
+// to assertions as "value".
+func (mc *MonitorChecker) checkRedis(ctx context.Context, check *models.MonitorCheck) error {
+	u, err := url.Parse(mc.monitor.URL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %v", err)
+	}
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "6379")
+	}
+
+	timeout := time.Duration(mc.monitor.Timeout) * time.Second
+	conn, err := dial(ctx, "tcp", address, timeout)
+	if err != nil {
+		return fmt.Errorf("connection failed to %s: %v", address, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if u.Scheme == "rediss" || mc.monitor.TLSMode == models.TLSModeTLS {
+		if conn, err = mc.upgradeTLS(conn, u.Hostname(), check); err != nil {
+			return err
+		}
+		defer conn.Close()
+	}
+
+	rc := &redisConn{conn: conn, reader: bufio.NewReaderSize(conn, maxRedisLine)}
+
+	username, password := mc.monitor.AuthUsername, mc.monitor.AuthPassword
+	if username == "" && u.User != nil {
+		username = u.User.Username()
+		password, _ = u.User.Password()
+	}
+	if password != "" {
+		args := []string{"AUTH", password}
+		if username != "" && username != "default" {
+			args = []string{"AUTH", username, password}
+		}
+		if _, err := rc.do(args...); err != nil {
+			return fmt.Errorf("authentication failed: %v", err)
+		}
+	}
+
+	if db := strings.Trim(u.Path, "/"); db != "" && db != "0" {
+		if _, err := rc.do("SELECT", db); err != nil {
+			return fmt.Errorf("SELECT %s failed: %v", db, err)
+		}
+	}
+
+	args := strings.Fields(mc.monitor.DBQuery)
+	if len(args) == 0 {
+		args = []string{"PING"}
+	}
+	reply, err := rc.do(args...)
+	if err != nil {
+		return fmt.Errorf("%s failed: %v", strings.ToUpper(args[0]), err)
+	}
+
+	doc := map[string]interface{}{"value": reply}
+	return mc.assertResult(check, doc, fmt.Sprintf("%s returned %s", strings.ToUpper(args[0]), describeJSON(reply)))
+}
+
+// redisConn speaks the RESP2 protocol. Replies are decoded into the types
+// used by decoded JSON so the assertion operators apply to them.
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// do sends a command and reads its reply. Error replies are returned as
+// errors.
+func (rc *redisConn) do(args ...string) (interface{}, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(rc.conn, b.String()); err != nil {
+		return nil, err
+	}
+	return rc.readReply(0)
+}
+
+// readReply reads a reply nested in depth arrays
+func (rc *redisConn) readReply(depth int) (interface{}, error) {
+	raw, err := rc.reader.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		return nil, fmt.Errorf("reply line longer than %d bytes", maxRedisLine)
+	}
+	if err != nil {
+		return nil, err
+	}
+	line := strings.TrimRight(string(raw), "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("empty reply")
+	}
+
+	payload := line[1:]
+	switch line[0] {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, fmt.Errorf("%s", payload)
+	case ':':
+		if _, err := strconv.ParseInt(payload, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid integer reply: %q", payload)
+		}
+		return json.Number(payload), nil
+	case '$':
+		n, err := rc.length(payload)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(rc.reader, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		if depth == maxRedisDepth {
+			return nil, fmt.Errorf("reply nested more than %d arrays deep", maxRedisDepth)
+		}
+		n, err := rc.length(payload)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		elements := make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			element, err := rc.readReply(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, element)
+		}
+		return elements, nil
+	}
+
+	return nil, fmt.Errorf("unexpected reply: %q", snippet(line, 0, 0))
+}
+
+// length parses a bulk string or array length; -1 denotes a nil reply
+func (rc *redisConn) length(payload string) (int, error) {
+	n, err := strconv.Atoi(payload)
+	if err != nil || n < -1 || n > maxRedisReply {
+		return 0, fmt.Errorf("invalid reply length: %q", payload)
+	}
+	return n, nil
+}
@@ -0,0 +1,255 @@
+package monitoring
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"uptime-monitor/internal/models"
+)
+
+// fakeRedis answers RESP commands from a table of raw replies, keyed by the
+// command and its arguments joined with spaces, and records the commands
+type fakeRedis struct {
+	mu       sync.Mutex
+	commands []string
+}
+
+func startRedis(t *testing.T, replies map[string]string) (string, *fakeRedis) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	t.Cleanup(func() {
+		listener.Close()
+		wg.Wait()
+	})
+
+	server := &fakeRedis{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer conn.Close()
+				conn.SetDeadline(time.Now().Add(5 * time.Second))
+				reader := bufio.NewReader(conn)
+				for {
+					command, err := readRESPCommand(reader)
+					if err != nil {
+						return
+					}
+					server.mu.Lock()
+					server.commands = append(server.commands, command)
+					server.mu.Unlock()
+
+					reply, ok := replies[command]
+					if !ok {
+						reply = "-ERR unknown command\r\n"
+					}
+					io.WriteString(conn, reply)
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String(), server
+}
+
+// readRESPCommand reads an array of bulk strings
+func readRESPCommand(reader *bufio.Reader) (string, error) {
+	readLine := func(prefix byte) (int, error) {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return 0, err
+		}
+		if line[0] != prefix {
+			return 0, fmt.Errorf("unexpected line %q", line)
+		}
+		return strconv.Atoi(strings.TrimRight(line[1:], "\r\n"))
+	}
+
+	n, err := readLine('*')
+	if err != nil {
+		return "", err
+	}
+	args := make([]string, n)
+	for i := range args {
+		length, err := readLine('$')
+		if err != nil {
+			return "", err
+		}
+		buf := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return "", err
+		}
+		args[i] = string(buf[:length])
+	}
+	return strings.Join(args, " "), nil
+}
+
+func TestRedisCheck(t *testing.T) {
+	nested := strings.Repeat("*1\r\n", maxRedisDepth+1) + ":1\r\n"
+	withinDepth := strings.Repeat("*1\r\n", maxRedisDepth) + ":1\r\n"
+	assert := func(operator, expected string) models.JSONAssertions {
+		return models.JSONAssertions{{Path: "value", Operator: operator, Expected: expected}}
+	}
+
+	tests := []struct {
+		name     string
+		url      string // appended to redis://
+		monitor  models.Monitor
+		replies  map[string]string
+		commands string // sent to the server, separated by "|"
+		err      string // expected failure; empty when the check passes
+		message  string
+	}{
+		{
+			name:     "ping",
+			replies:  map[string]string{"PING": "+PONG\r\n"},
+			commands: "PING",
+			message:  `PING returned "PONG"`,
+		},
+		{
+			name:     "password and database from the URL",
+			url:      ":s3cret@%s/2",
+			replies:  map[string]string{"AUTH s3cret": "+OK\r\n", "SELECT 2": "+OK\r\n", "PING": "+PONG\r\n"},
+			commands: "AUTH s3cret|SELECT 2|PING",
+			message:  `PING returned "PONG"`,
+		},
+		{
+			name:     "monitor credentials win",
+			url:      "old:pass@%s",
+			monitor:  models.Monitor{AuthUsername: "monitor", AuthPassword: "s3cret"},
+			replies:  map[string]string{"AUTH monitor s3cret": "+OK\r\n", "PING": "+PONG\r\n"},
+			commands: "AUTH monitor s3cret|PING",
+			message:  `PING returned "PONG"`,
+		},
+		{
+			name:     "default user sends the password only",
+			monitor:  models.Monitor{AuthUsername: "default", AuthPassword: "s3cret"},
+			replies:  map[string]string{"AUTH s3cret": "+OK\r\n", "PING": "+PONG\r\n"},
+			commands: "AUTH s3cret|PING",
+			message:  `PING returned "PONG"`,
+		},
+		{
+			name:     "wrong password",
+			monitor:  models.Monitor{AuthPassword: "wrong"},
+			replies:  map[string]string{"AUTH wrong": "-WRONGPASS invalid username-password pair\r\n"},
+			commands: "AUTH wrong",
+			err:      "authentication failed: WRONGPASS invalid username-password pair",
+		},
+		{
+			name:     "key below threshold",
+			monitor:  models.Monitor{DBQuery: "LLEN queue:jobs", JSONAssertions: assert(OpLess, "100")},
+			replies:  map[string]string{"LLEN queue:jobs": ":42\r\n"},
+			commands: "LLEN queue:jobs",
+			message:  "LLEN returned 42",
+		},
+		{
+			name:     "key above threshold",
+			monitor:  models.Monitor{DBQuery: "LLEN queue:jobs", JSONAssertions: assert(OpLess, "10")},
+			replies:  map[string]string{"LLEN queue:jobs": ":42\r\n"},
+			commands: "LLEN queue:jobs",
+			err:      `1 of 1 result assertions failed: value < "10" (got 42)`,
+		},
+		{
+			name:     "bulk string value",
+			monitor:  models.Monitor{DBQuery: "GET deploy:state", JSONAssertions: assert(OpEqual, "ready")},
+			replies:  map[string]string{"GET deploy:state": "$5\r\nready\r\n"},
+			commands: "GET deploy:state",
+			message:  `GET returned "ready"`,
+		},
+		{
+			name:     "missing key",
+			monitor:  models.Monitor{DBQuery: "GET deploy:state", JSONAssertions: assert(OpEqual, "ready")},
+			replies:  map[string]string{"GET deploy:state": "$-1\r\n"},
+			commands: "GET deploy:state",
+			err:      `1 of 1 result assertions failed: value == "ready" (got null)`,
+		},
+		{
+			name:     "array value",
+			monitor:  models.Monitor{DBQuery: "SMEMBERS workers", JSONAssertions: assert(OpContains, "w2")},
+			replies:  map[string]string{"SMEMBERS workers": "*2\r\n$2\r\nw1\r\n$2\r\nw2\r\n"},
+			commands: "SMEMBERS workers",
+			message:  `SMEMBERS returned ["w1","w2"]`,
+		},
+		{
+			name:     "error reply",
+			monitor:  models.Monitor{DBQuery: "FLUSHALL"},
+			replies:  map[string]string{},
+			commands: "FLUSHALL",
+			err:      "FLUSHALL failed: ERR unknown command",
+		},
+		{
+			name:     "malformed reply",
+			replies:  map[string]string{"PING": "PONG\r\n"},
+			commands: "PING",
+			err:      `PING failed: unexpected reply: "PONG"`,
+		},
+		{
+			name:     "nesting within the limit",
+			monitor:  models.Monitor{DBQuery: "XREAD"},
+			replies:  map[string]string{"XREAD": withinDepth},
+			commands: "XREAD",
+			message:  "XREAD returned " + strings.Repeat("[", maxRedisDepth) + "1" + strings.Repeat("]", maxRedisDepth),
+		},
+		{
+			name:     "nesting too deep",
+			monitor:  models.Monitor{DBQuery: "XREAD"},
+			replies:  map[string]string{"XREAD": nested},
+			commands: "XREAD",
+			err:      "XREAD failed: reply nested more than 16 arrays deep",
+		},
+		{
+			name:     "overlong line",
+			replies:  map[string]string{"PING": "+" + strings.Repeat("x", maxRedisLine) + "\r\n"},
+			commands: "PING",
+			err:      "PING failed: reply line longer than 65536 bytes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address, server := startRedis(t, tt.replies)
+			monitor := tt.monitor
+			monitor.Type = "redis"
+			monitor.URL = "redis://" + address
+			if tt.url != "" {
+				monitor.URL = "redis://" + fmt.Sprintf(tt.url, address)
+			}
+			monitor.Timeout = 5
+			mc := &MonitorChecker{monitor: monitor, manager: &Manager{}}
+
+			var check models.MonitorCheck
+			err := mc.checker().Check(context.Background(), &check)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("error %v, want %q", err, tt.err)
+				}
+			} else if err != nil {
+				t.Error(err)
+			} else if check.Status != "up" || check.Message != tt.message {
+				t.Errorf("got %s %q, want up %q", check.Status, check.Message, tt.message)
+			}
+
+			server.mu.Lock()
+			defer server.mu.Unlock()
+			if got := strings.Join(server.commands, "|"); got != tt.commands {
+				t.Errorf("commands %q, want %q", got, tt.commands)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package monitoring
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"uptime-monitor/internal/models"
+)
+
+// MonitorType describes a kind of monitor: how to check it, which settings
+// it uses and how to validate them. The descriptive fields are served to
+// the UI so it can render the type's form.
+type MonitorType struct {
+	Name        string      `json:"name"` // the monitor's type value
+	Label       string      `json:"label"`
+	Description string      `json:"description"`
+	Fields      []TypeField `json:"fields"` // type-specific settings; common ones are not repeated
+
+	// NewChecker returns the checker for one monitor; nil means the type
+	// is not checked on a schedule (push monitors)
+	NewChecker func(mc *MonitorChecker) Checker `json:"-"`
+	// Validate checks the type-specific settings of a normalized monitor
+	Validate func(monitor models.Monitor) FieldErrors `json:"-"`
+}
+
+// TypeField describes one monitor setting for the UI
+type TypeField struct {
+	Name     string      `json:"name"` // the monitor's JSON field
+	Label    string      `json:"label"`
+	Kind     string      `json:"type"`
+	Required bool        `json:"required,omitempty"`
+	Options  []string    `json:"options,omitempty"` // values for select fields
+	Default  interface{} `json:"default,omitempty"`
+	Help     string      `json:"help,omitempty"`
+}
+
+// Field kinds
+const (
+	FieldString  = "string"
+	FieldText    = "text"   // multi-line string
+	FieldSecret  = "secret" // never returned by the API
+	FieldNumber  = "number"
+	FieldBoolean = "boolean"
+	FieldSelect  = "select"
+	FieldList    = "list" // list of strings
+	FieldMap     = "map"  // string to string
+	FieldJSON    = "json" // structured value, e.g. assertions
+)
+
+// FieldError is a validation failure for one monitor setting
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// FieldErrors collects the validation failures of a monitor
+type FieldErrors []FieldError
+
+func (e FieldErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fe := range e {
+		messages[i] = fmt.Sprintf("%s: %s", fe.Field, fe.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Add records a failure for field
+func (e *FieldErrors) Add(field, format string, args ...interface{}) {
+	*e = append(*e, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+var registry = struct {
+	sync.RWMutex
+	types map[string]*MonitorType
+	order []string
+}{types: map[string]*MonitorType{}}
+
+// RegisterMonitorType makes a monitor type available. Registering a name
+// twice panics.
+func RegisterMonitorType(t MonitorType) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, ok := registry.types[t.Name]; ok {
+		panic(fmt.Sprintf("monitor type %s registered twice", t.Name))
+	}
+	registry.types[t.Name] = &t
+	registry.order = append(registry.order, t.Name)
+}
+
+// LookupMonitorType returns the registered type with the given name
+func LookupMonitorType(name string) (MonitorType, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	t, ok := registry.types[name]
+	if !ok {
+		return MonitorType{}, false
+	}
+	return *t, true
+}
+
+// MonitorTypes returns the registered types in registration order
+func MonitorTypes() []MonitorType {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	types := make([]MonitorType, len(registry.order))
+	for i, name := range registry.order {
+		types[i] = *registry.types[name]
+	}
+	return types
+}
+
+// ValidateMonitor rejects unknown types, select settings outside the
+// type's options and anything the type's own validation finds. The error
+// is a FieldErrors.
+func ValidateMonitor(monitor models.Monitor) error {
+	t, ok := LookupMonitorType(monitor.Type)
+	if !ok {
+		return FieldErrors{{Field: "type", Message: fmt.Sprintf("unknown monitor type: %q", monitor.Type)}}
+	}
+
+	errs := validateOptions(t.Fields, monitor)
+	if t.Validate != nil {
+		errs = append(errs, t.Validate(monitor)...)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Monitor returns the monitor being checked, for checkers registered
+// outside this package
+func (mc *MonitorChecker) Monitor() models.Monitor {
+	return mc.monitor
+}
@@ -0,0 +1,130 @@
+package monitoring
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// lateStartThreshold is how long a due check may wait for a worker before
+// it counts as a late start
+const lateStartThreshold = time.Second
+
+// jitterSchedule fires every interval at a fixed offset, so monitors with
+// the same interval are spread across it instead of firing together
+type jitterSchedule struct {
+	interval time.Duration
+	offset   time.Duration
+}
+
+// newJitterSchedule derives the offset from the monitor ID, which keeps a
+// monitor's place in the interval stable across restarts
+func newJitterSchedule(monitorID int, interval time.Duration) jitterSchedule {
+	h := fnv.New32a()
+	h.Write([]byte(strconv.Itoa(monitorID)))
+	offset := time.Duration(h.Sum32()) * time.Millisecond % interval
+	return jitterSchedule{interval: interval, offset: offset}
+}
+
+func (s jitterSchedule) Next(t time.Time) time.Time {
+	next := t.Truncate(s.interval).Add(s.offset)
+	for !next.After(t) {
+		next = next.Add(s.interval)
+	}
+	return next
+}
+
+// SchedulerStats describes the worker pool; counters run from startup
+type SchedulerStats struct {
+	Workers             int     `json:"workers"`
+	BusyWorkers         int     `json:"busy_workers"`
+	QueueDepth          int64   `json:"queue_depth"` // due checks waiting for a worker
+	Monitors            int     `json:"monitors"`
+	ChecksStarted       int64   `json:"checks_started"`
+	SkippedStillRunning int64   `json:"skipped_still_running"` // due while the previous check was queued or running
+	LateStarts          int64   `json:"late_starts"`           // waited longer than a second for a worker
+	MaxStartDelay       float64 `json:"max_start_delay"`       // milliseconds
+}
+
+// schedulerCounters are updated by the dispatching goroutines
+type schedulerCounters struct {
+	queued        atomic.Int64
+	started       atomic.Int64
+	skipped       atomic.Int64
+	late          atomic.Int64
+	maxStartDelay atomic.Int64 // nanoseconds
+}
+
+// dispatch runs a due check. A check still queued or running from its
+// previous tick, or waiting to re-check, is skipped. Each attempt takes a
+// worker only while it runs, so monitors waiting between re-checks do not
+// hold up the others.
+func (m *Manager) dispatch(checker *MonitorChecker) {
+	if !checker.busy.CompareAndSwap(false, true) {
+		m.counters.skipped.Add(1)
+		return
+	}
+	defer checker.busy.Store(false)
+
+	checker.check(checker.ctx)
+}
+
+// acquireWorker waits for a free worker and returns the function that
+// frees it. It gives up when ctx is done or the manager stops.
+func (m *Manager) acquireWorker(ctx context.Context) (func(), error) {
+	due := time.Now()
+	m.counters.queued.Add(1)
+	select {
+	case m.workers <- struct{}{}:
+		m.counters.queued.Add(-1)
+	case <-m.stopping:
+		m.counters.queued.Add(-1)
+		return nil, ErrStopping
+	case <-ctx.Done():
+		m.counters.queued.Add(-1)
+		return nil, ctx.Err()
+	}
+	release := func() { <-m.workers }
+
+	// A worker may come free just as the manager stops
+	select {
+	case <-m.stopping:
+		release()
+		return nil, ErrStopping
+	default:
+	}
+
+	delay := time.Since(due)
+	if delay > lateStartThreshold {
+		m.counters.late.Add(1)
+	}
+	for {
+		max := m.counters.maxStartDelay.Load()
+		if int64(delay) <= max || m.counters.maxStartDelay.CompareAndSwap(max, int64(delay)) {
+			break
+		}
+	}
+
+	m.counters.started.Add(1)
+	return release, nil
+}
+
+// SchedulerStats returns the worker pool's current state and counters
+func (m *Manager) SchedulerStats() SchedulerStats {
+	m.mu.RLock()
+	monitors := len(m.checkers)
+	m.mu.RUnlock()
+
+	return SchedulerStats{
+		Workers:             cap(m.workers),
+		BusyWorkers:         len(m.workers),
+		QueueDepth:          m.counters.queued.Load(),
+		Monitors:            monitors,
+		ChecksStarted:       m.counters.started.Load(),
+		SkippedStillRunning: m.counters.skipped.Load(),
+		LateStarts:          m.counters.late.Load(),
+		MaxStartDelay:       *durationMillis(time.Duration(m.counters.maxStartDelay.Load())),
+	}
+}
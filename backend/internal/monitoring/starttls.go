@@ -0,0 +1,185 @@
+package monitoring
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// STARTTLS protocols supported by TCP and mail monitors
+const (
+	StartTLSSMTP = "smtp"
+	StartTLSIMAP = "imap"
+	StartTLSPOP3 = "pop3"
+)
+
+// startTLS performs the plaintext part of a STARTTLS negotiation. On
+// success the caller wraps conn in TLS.
+func startTLS(conn net.Conn, protocol string) error {
+	tc := newTextConn(conn)
+
+	switch protocol {
+	case StartTLSSMTP:
+		if _, _, err := tc.smtpReply(220); err != nil {
+			return err
+		}
+		if _, _, err := tc.smtpCommand(250, "EHLO %s", heloName); err != nil {
+			return err
+		}
+		_, _, err := tc.smtpCommand(220, "STARTTLS")
+		return err
+	case StartTLSIMAP:
+		if _, err := tc.imapGreeting(); err != nil {
+			return err
+		}
+		_, err := tc.imapCommand("STARTTLS")
+		return err
+	case StartTLSPOP3:
+		if _, err := tc.pop3Reply(); err != nil {
+			return err
+		}
+		_, err := tc.pop3Command("STLS")
+		return err
+	}
+
+	return fmt.Errorf("unsupported STARTTLS protocol: %s", protocol)
+}
+
+// heloName identifies the monitor in SMTP greetings
+const heloName = "uptime-monitor.localhost"
+
+// Limits on what a mail server may send, so a hostile one cannot exhaust
+// memory. Protocol lines are far shorter: SMTP replies are limited to 512
+// bytes.
+const (
+	maxLineLength = 8192
+	maxReplyLines = 1000 // lines in one multi-line reply
+)
+
+// textConn speaks line-based mail protocols over a connection
+type textConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	tag    int
+}
+
+func newTextConn(conn net.Conn) *textConn {
+	return &textConn{conn: conn, reader: bufio.NewReaderSize(conn, maxLineLength)}
+}
+
+func (t *textConn) readLine() (string, error) {
+	line, err := t.reader.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		return "", fmt.Errorf("line longer than %d bytes", maxLineLength)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+func (t *textConn) writeLine(format string, args ...interface{}) error {
+	_, err := fmt.Fprintf(t.conn, format+"\r\n", args...)
+	return err
+}
+
+// smtpReply reads a possibly multi-line reply and checks its code
+func (t *textConn) smtpReply(expected int) (int, []string, error) {
+	var lines []string
+	for {
+		line, err := t.readLine()
+		if err != nil {
+			return 0, lines, err
+		}
+		if len(line) < 3 {
+			return 0, lines, fmt.Errorf("malformed SMTP reply: %q", line)
+		}
+
+		var code int
+		if _, err := fmt.Sscanf(line[:3], "%d", &code); err != nil {
+			return 0, lines, fmt.Errorf("malformed SMTP reply: %q", line)
+		}
+		text := ""
+		if len(line) > 3 {
+			text = strings.TrimSpace(line[4:])
+		}
+		lines = append(lines, text)
+		if len(lines) > maxReplyLines {
+			return 0, lines, fmt.Errorf("SMTP reply longer than %d lines", maxReplyLines)
+		}
+
+		// "250-" continues a reply, "250 " ends it
+		if len(line) == 3 || line[3] != '-' {
+			if code != expected {
+				return code, lines, fmt.Errorf("unexpected SMTP reply: %s", line)
+			}
+			return code, lines, nil
+		}
+	}
+}
+
+func (t *textConn) smtpCommand(expected int, format string, args ...interface{}) (int, []string, error) {
+	if err := t.writeLine(format, args...); err != nil {
+		return 0, nil, err
+	}
+	return t.smtpReply(expected)
+}
+
+func (t *textConn) imapGreeting() (string, error) {
+	line, err := t.readLine()
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(line, "* OK") && !strings.HasPrefix(line, "* PREAUTH") {
+		return line, fmt.Errorf("unexpected IMAP greeting: %s", line)
+	}
+	return line, nil
+}
+
+// imapCommand sends a tagged command and returns the untagged lines that
+// preceded a tagged OK
+func (t *textConn) imapCommand(format string, args ...interface{}) ([]string, error) {
+	t.tag++
+	tag := fmt.Sprintf("a%03d", t.tag)
+	if err := t.writeLine(tag+" "+format, args...); err != nil {
+		return nil, err
+	}
+
+	var untagged []string
+	for {
+		line, err := t.readLine()
+		if err != nil {
+			return untagged, err
+		}
+		if !strings.HasPrefix(line, tag+" ") {
+			if len(untagged) == maxReplyLines {
+				return untagged, fmt.Errorf("IMAP response longer than %d lines", maxReplyLines)
+			}
+			untagged = append(untagged, line)
+			continue
+		}
+		if !strings.HasPrefix(line, tag+" OK") {
+			return untagged, fmt.Errorf("IMAP command failed: %s", strings.TrimPrefix(line, tag+" "))
+		}
+		return untagged, nil
+	}
+}
+
+func (t *textConn) pop3Reply() (string, error) {
+	line, err := t.readLine()
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(line, "+OK") {
+		return line, fmt.Errorf("unexpected POP3 reply: %s", line)
+	}
+	return line, nil
+}
+
+func (t *textConn) pop3Command(format string, args ...interface{}) (string, error) {
+	if err := t.writeLine(format, args...); err != nil {
+		return "", err
+	}
+	return t.pop3Reply()
+}
@@ -0,0 +1,64 @@
+package monitoring
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// defaultStatusCodes are accepted when a monitor does not list its own
+const defaultStatusCodes = "200-399"
+
+// ValidateStatusCodes checks a list of codes and ranges such as
+// "200-299,401"
+func ValidateStatusCodes(spec string) error {
+	_, err := parseStatusCodes(spec)
+	return err
+}
+
+type statusRange struct {
+	from, to int
+}
+
+func parseStatusCodes(spec string) ([]statusRange, error) {
+	var ranges []statusRange
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		from, to, isRange := strings.Cut(part, "-")
+		if !isRange {
+			to = from
+		}
+		low, errLow := strconv.Atoi(strings.TrimSpace(from))
+		high, errHigh := strconv.Atoi(strings.TrimSpace(to))
+		if errLow != nil || errHigh != nil || low < 100 || high > 599 || low > high {
+			return nil, fmt.Errorf("invalid status code or range: %q", part)
+		}
+		ranges = append(ranges, statusRange{low, high})
+	}
+
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("no accepted status codes")
+	}
+	return ranges, nil
+}
+
+// statusAccepted reports whether code is within the accepted list
+func statusAccepted(spec string, code int) bool {
+	if spec == "" {
+		spec = defaultStatusCodes
+	}
+	ranges, err := parseStatusCodes(spec)
+	if err != nil {
+		return false
+	}
+	for _, r := range ranges {
+		if code >= r.from && code <= r.to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+package monitoring
+
+import (
+	"time"
+	"uptime-monitor/internal/models"
+)
+
+// runStep times fn and records it as a named step on the check
+func runStep(check *models.MonitorCheck, name string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+
+	step := models.CheckStep{
+		Name:     name,
+		Duration: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		step.Error = err.Error()
+	}
+	check.Steps = append(check.Steps, step)
+	return err
+}
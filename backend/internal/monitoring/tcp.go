@@ -0,0 +1,169 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+	"uptime-monitor/internal/models"
+)
+
+// tcpReadIdle ends a response read once the server has gone quiet
+const tcpReadIdle = time.Second
+
+func (mc *MonitorChecker) checkTCP(ctx context.Context, check *models.MonitorCheck) error {
+	address, err := tcpAddress(mc.monitor.URL)
+	if err != nil {
+		return err
+	}
+
+	// Try to connect
+	timeout := time.Duration(mc.monitor.Timeout) * time.Second
+	conn, err := dial(ctx, mc.dialNetwork("tcp"), address, timeout)
+	if err != nil {
+		return fmt.Errorf("TCP connection failed to %s: %v", address, err)
+	}
+	defer conn.Close()
+
+	if mc.monitor.TLSMode == "" && mc.monitor.TCPSend == "" && mc.monitor.Keyword == "" {
+		check.Status = "up"
+		check.Message = fmt.Sprintf("TCP connection successful to %s", address)
+		return nil
+	}
+
+	deadline := time.Now().Add(timeout)
+	conn.SetDeadline(deadline)
+
+	if mc.monitor.TLSMode != "" {
+		host, _, _ := net.SplitHostPort(address)
+		if conn, err = mc.upgradeTLS(conn, host, check); err != nil {
+			return err
+		}
+		defer conn.Close()
+	}
+
+	if mc.monitor.TCPSend != "" {
+		if _, err := conn.Write([]byte(mc.monitor.TCPSend)); err != nil {
+			return fmt.Errorf("failed to send payload to %s: %v", address, err)
+		}
+	}
+
+	var response []byte
+	if mc.monitor.TCPSend != "" || mc.monitor.Keyword != "" {
+		if response, err = mc.readResponse(conn, deadline); err != nil {
+			return fmt.Errorf("failed to read response from %s: %v", address, err)
+		}
+	}
+
+	if mc.monitor.Keyword != "" {
+		failure, err := mc.matchKeyword(response)
+		if err != nil {
+			return err
+		}
+		if failure != "" {
+			check.Status = "down"
+			check.Message = failure
+			return nil
+		}
+	}
+
+	check.Status = "up"
+	check.Message = fmt.Sprintf("TCP connection successful to %s", address)
+	if len(response) > 0 {
+		check.Message += fmt.Sprintf(", response: %q", snippet(string(response), 0, 0))
+	}
+	return nil
+}
+
+// tcpAddress accepts tcp://host:port or host:port
+func tcpAddress(target string) (string, error) {
+	address := target
+
+	// Handle different URL formats for TCP
+	if strings.HasPrefix(target, "tcp://") {
+		u, err := url.Parse(target)
+		if err != nil {
+			return "", fmt.Errorf("invalid TCP URL: %v", err)
+		}
+		address = u.Host
+	}
+
+	if address == "" {
+		return "", fmt.Errorf("no host:port specified")
+	}
+
+	// Validate address format (should be host:port)
+	if !strings.Contains(address, ":") {
+		return "", fmt.Errorf("TCP check requires host:port format, got: %s", address)
+	}
+
+	return address, nil
+}
+
+// upgradeTLS wraps the connection in TLS, negotiating STARTTLS first when
+// the monitor asks for it. Certificate details are recorded on the check.
+func (mc *MonitorChecker) upgradeTLS(conn net.Conn, host string, check *models.MonitorCheck) (net.Conn, error) {
+	if protocol, ok := strings.CutPrefix(mc.monitor.TLSMode, "starttls-"); ok {
+		if err := startTLS(conn, protocol); err != nil {
+			return nil, fmt.Errorf("STARTTLS failed: %v", err)
+		}
+	}
+
+	config, err := mc.tlsConfig(check)
+	if err != nil {
+		return nil, err
+	}
+	config.ServerName = host
+
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, fmt.Errorf("TLS handshake failed: %v", err)
+	}
+	return tlsConn, nil
+}
+
+// readResponse reads up to the monitor's limit, stopping early once the
+// expected keyword has been seen, the peer closes, or it stays quiet for
+// tcpReadIdle after sending something
+func (mc *MonitorChecker) readResponse(conn net.Conn, deadline time.Time) ([]byte, error) {
+	limit := mc.monitor.MaxBodyBytes
+	if limit <= 0 {
+		limit = defaultMaxBodyBytes
+	}
+
+	response := make([]byte, 0, 4096)
+	buf := make([]byte, 4096)
+	for len(response) < limit {
+		if len(response) > 0 {
+			idle := time.Now().Add(tcpReadIdle)
+			if idle.Before(deadline) {
+				conn.SetReadDeadline(idle)
+			}
+		}
+
+		n, err := conn.Read(buf[:min(len(buf), limit-len(response))])
+		response = append(response, buf[:n]...)
+
+		if mc.monitor.Keyword != "" && !mc.monitor.KeywordInvert {
+			if failure, _ := mc.matchKeyword(response); failure == "" {
+				break
+			}
+		}
+
+		if err != nil {
+			var netErr net.Error
+			timedOut := errors.As(err, &netErr) && netErr.Timeout()
+			if errors.Is(err, io.EOF) || (timedOut && len(response) > 0) {
+				break
+			}
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
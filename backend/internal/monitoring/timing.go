@@ -0,0 +1,106 @@
+package monitoring
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+	"uptime-monitor/internal/models"
+)
+
+// httpTiming collects the phases of an HTTP request from httptrace. Phases
+// that repeat, for redirects or dual-stack dialing, are summed.
+type httpTiming struct {
+	mu sync.Mutex
+
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+
+	dns     time.Duration
+	connect time.Duration
+	tls     time.Duration
+	ttfb    time.Duration
+}
+
+func (t *httpTiming) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.start(&t.dnsStart)
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.done(&t.dnsStart, &t.dns)
+		},
+		ConnectStart: func(network, addr string) {
+			t.start(&t.connectStart)
+		},
+		ConnectDone: func(network, addr string, err error) {
+			if err == nil {
+				t.done(&t.connectStart, &t.connect)
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.start(&t.tlsStart)
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.done(&t.tlsStart, &t.tls)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.start(&t.wroteRequest)
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.firstByte = time.Now()
+			if !t.wroteRequest.IsZero() {
+				t.ttfb += t.firstByte.Sub(t.wroteRequest)
+				t.wroteRequest = time.Time{}
+			}
+		},
+	}
+}
+
+// start marks the beginning of a phase unless one is already in progress,
+// so parallel dials count from the first attempt
+func (t *httpTiming) start(at *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if at.IsZero() {
+		*at = time.Now()
+	}
+}
+
+func (t *httpTiming) done(at *time.Time, total *time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !at.IsZero() {
+		*total += time.Since(*at)
+		*at = time.Time{}
+	}
+}
+
+// record stores the phases on the check; bodyRead is when the response body
+// was consumed, or zero when there was no response
+func (t *httpTiming) record(check *models.MonitorCheck, bodyRead time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	check.DNSTime = phaseMillis(t.dns)
+	check.ConnectTime = phaseMillis(t.connect)
+	check.TLSTime = phaseMillis(t.tls)
+	check.TTFB = phaseMillis(t.ttfb)
+	if !bodyRead.IsZero() && !t.firstByte.IsZero() {
+		check.TransferTime = phaseMillis(bodyRead.Sub(t.firstByte))
+	}
+}
+
+// phaseMillis is durationMillis with nil for a phase that did not happen,
+// e.g. no DNS lookup for an IP address
+func phaseMillis(d time.Duration) *float64 {
+	if d <= 0 {
+		return nil
+	}
+	return durationMillis(d)
+}
@@ -0,0 +1,186 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"regexp"
+	"uptime-monitor/internal/models"
+)
+
+// variablePattern matches {{name}} placeholders in transaction steps
+var variablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+// VariableName matches the names captures may store values under
+var VariableName = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// checkTransaction runs the monitor's steps in order with a shared cookie
+// jar and stops at the first step whose request or assertions fail. Each
+// step's timing is recorded on the check.
+func (mc *MonitorChecker) checkTransaction(ctx context.Context, check *models.MonitorCheck) error {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return err
+	}
+	client, err := mc.httpClient(check)
+	if err != nil {
+		return err
+	}
+	defer client.CloseIdleConnections()
+	client.Jar = jar
+
+	variables := map[string]string{}
+	for i, step := range mc.monitor.TransactionSteps {
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprintf("step %d", i+1)
+		}
+
+		err := runStep(check, name, func() error {
+			return mc.runTransactionStep(ctx, client, step, variables, check)
+		})
+		if err != nil {
+			return fmt.Errorf("step %d (%s) failed: %v", i+1, name, err)
+		}
+	}
+
+	check.Status = "up"
+	check.Message = fmt.Sprintf("All %d steps passed", len(mc.monitor.TransactionSteps))
+	return nil
+}
+
+// runTransactionStep sends one step's request, checks its response and
+// stores the step's captures in variables
+func (mc *MonitorChecker) runTransactionStep(ctx context.Context, client *http.Client, step models.TransactionStep, variables map[string]string, check *models.MonitorCheck) error {
+	sc, err := mc.stepChecker(step, variables)
+	if err != nil {
+		return err
+	}
+
+	req, err := sc.newRequest(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	check.StatusCode = resp.StatusCode
+	if !statusAccepted(sc.monitor.AcceptedStatusCodes, resp.StatusCode) {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	body, err := sc.readLimited(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	failure, err := sc.assertBody(body)
+	if err != nil {
+		return err
+	}
+	if failure != "" {
+		return errors.New(failure)
+	}
+
+	return captureVariables(step.Captures, body, variables)
+}
+
+// stepChecker returns a checker for one step, with the step's request and
+// assertions in place of the monitor's own and variables expanded. Step
+// headers are added to the monitor's headers.
+func (mc *MonitorChecker) stepChecker(step models.TransactionStep, variables map[string]string) (*MonitorChecker, error) {
+	var undefined error
+	expand := func(s string) string {
+		return variablePattern.ReplaceAllStringFunc(s, func(placeholder string) string {
+			name := variablePattern.FindStringSubmatch(placeholder)[1]
+			value, ok := variables[name]
+			if !ok && undefined == nil {
+				undefined = fmt.Errorf("undefined variable %q", name)
+			}
+			return value
+		})
+	}
+
+	monitor := mc.monitor
+	monitor.URL = expand(step.URL)
+	monitor.Method = step.Method
+	monitor.Body = expand(step.Body)
+	monitor.Headers = models.StringMap{}
+	for name, value := range mc.monitor.Headers {
+		monitor.Headers[name] = value
+	}
+	for name, value := range step.Headers {
+		monitor.Headers[name] = expand(value)
+	}
+
+	monitor.AcceptedStatusCodes = step.AcceptedStatusCodes
+	if monitor.AcceptedStatusCodes == "" {
+		monitor.AcceptedStatusCodes = "200-399"
+	}
+	monitor.Keyword = step.Keyword
+	monitor.KeywordRegex = step.KeywordRegex
+	monitor.KeywordInvert = step.KeywordInvert
+	monitor.HTTPMode = models.HTTPModeStatus
+	monitor.JSONAssertions = step.JSONAssertions
+	if len(step.JSONAssertions) > 0 {
+		monitor.HTTPMode = models.HTTPModeJSON
+	}
+
+	return &MonitorChecker{monitor: monitor, manager: mc.manager, dryRun: mc.dryRun}, undefined
+}
+
+// captureVariables extracts the step's captures from a response body
+func captureVariables(captures []models.StepCapture, body []byte, variables map[string]string) error {
+	var doc interface{}
+	for _, capture := range captures {
+		if capture.Regex != "" {
+			re, err := regexp.Compile(capture.Regex)
+			if err != nil {
+				return fmt.Errorf("invalid regex for capture %s: %v", capture.Name, err)
+			}
+			match := re.FindSubmatch(body)
+			if match == nil {
+				return fmt.Errorf("capture %s: no match for %s", capture.Name, capture.Regex)
+			}
+			value := match[0]
+			if len(match) > 1 {
+				value = match[1]
+			}
+			variables[capture.Name] = string(value)
+			continue
+		}
+
+		if doc == nil {
+			var err error
+			if doc, err = decodeJSON(body); err != nil {
+				return fmt.Errorf("capture %s: response is not valid JSON: %v", capture.Name, err)
+			}
+		}
+		segments, err := parsePath(capture.Path)
+		if err != nil {
+			return fmt.Errorf("capture %s: %v", capture.Name, err)
+		}
+		value, found := lookupJSON(doc, segments)
+		if !found {
+			return fmt.Errorf("capture %s: path %s not found", capture.Name, capture.Path)
+		}
+		variables[capture.Name] = jsonText(value)
+	}
+	return nil
+}
+
+// jsonText returns strings as they are and other values as JSON
+func jsonText(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(value)
+	return string(b)
+}
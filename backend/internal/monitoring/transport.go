@@ -0,0 +1,93 @@
+package monitoring
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"uptime-monitor/internal/models"
+)
+
+// ProxyDirect as a monitor's proxy URL bypasses the server's default proxy
+const ProxyDirect = "direct"
+
+// proxySchemes are the proxy URL schemes http.Transport can use
+var proxySchemes = map[string]bool{"http": true, "https": true, "socks5": true, "socks5h": true}
+
+// ValidateProxyURL checks an http://, https:// or socks5:// proxy URL
+func ValidateProxyURL(proxy string) error {
+	_, err := parseProxyURL(proxy)
+	return err
+}
+
+func parseProxyURL(proxy string) (*url.URL, error) {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %v", err)
+	}
+	if !proxySchemes[u.Scheme] || u.Host == "" {
+		return nil, fmt.Errorf("proxy URL must be http://, https://, socks5:// or socks5h:// with a host")
+	}
+	return u, nil
+}
+
+// proxy returns the Proxy function for the monitor's HTTP transport: the
+// monitor's own proxy, else the server default, else the environment
+func (mc *MonitorChecker) proxy() (func(*http.Request) (*url.URL, error), error) {
+	proxy := mc.monitor.ProxyURL
+	if proxy == "" {
+		proxy = mc.manager.proxyURL
+	}
+
+	switch proxy {
+	case "":
+		return http.ProxyFromEnvironment, nil
+	case ProxyDirect:
+		return nil, nil
+	}
+
+	u, err := parseProxyURL(proxy)
+	if err != nil {
+		return nil, err
+	}
+	return http.ProxyURL(u), nil
+}
+
+// ValidateTLSSettings checks the monitor's client certificate and CA bundle
+func ValidateTLSSettings(monitor models.Monitor) error {
+	if _, err := clientCertificates(monitor); err != nil {
+		return err
+	}
+	_, err := caBundle(monitor.TLSCABundle)
+	return err
+}
+
+// clientCertificates returns the monitor's client certificate, if any
+func clientCertificates(monitor models.Monitor) ([]tls.Certificate, error) {
+	if monitor.TLSClientCert == "" && monitor.TLSClientKey == "" {
+		return nil, nil
+	}
+	if monitor.TLSClientCert == "" || monitor.TLSClientKey == "" {
+		return nil, errors.New("tls_client_cert and tls_client_key must be set together")
+	}
+
+	cert, err := tls.X509KeyPair([]byte(monitor.TLSClientCert), []byte(monitor.TLSClientKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid client certificate: %v", err)
+	}
+	return []tls.Certificate{cert}, nil
+}
+
+// caBundle parses PEM certificates into a pool; an empty bundle returns nil
+func caBundle(bundle string) (*x509.CertPool, error) {
+	if bundle == "" {
+		return nil, nil
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(bundle)) {
+		return nil, errors.New("tls_ca_bundle contains no PEM certificates")
+	}
+	return pool, nil
+}
@@ -0,0 +1,261 @@
+package monitoring
+
+import (
+	"context"
+	"sort"
+	"uptime-monitor/internal/models"
+)
+
+// The built-in monitor types, in the order the UI lists them
+func init() {
+	RegisterMonitorType(MonitorType{
+		Name:        "http",
+		Label:       "HTTP(S)",
+		Description: "Requests a URL and checks the status code, body, JSON or content changes",
+		Fields:      httpFields,
+		NewChecker:  familyCheckerFor((*MonitorChecker).checkHTTP),
+		Validate:    validateHTTP,
+	})
+	// https is kept for monitors created before http covered both schemes
+	RegisterMonitorType(MonitorType{
+		Name:        "https",
+		Label:       "HTTPS",
+		Description: "Same as HTTP(S)",
+		Fields:      httpFields,
+		NewChecker:  familyCheckerFor((*MonitorChecker).checkHTTP),
+		Validate:    validateHTTP,
+	})
+	RegisterMonitorType(MonitorType{
+		Name:        "transaction",
+		Label:       "HTTP transaction",
+		Description: "Runs several HTTP requests in order with a shared cookie jar",
+		Fields: joinFields([]TypeField{
+			{Name: "transaction_steps", Label: "Steps", Kind: FieldJSON, Required: true, Help: "Requests to run in order; captures are available to later steps as {{name}}"},
+			{Name: "headers", Label: "Headers", Kind: FieldMap, Help: "Sent with every step"},
+		}, authFields, redirectFields, proxyFields, tlsFields, []TypeField{
+			{Name: "address_family", Label: "Address family", Kind: FieldSelect, Options: []string{
+				models.AddressFamilyAny, models.AddressFamilyIPv4, models.AddressFamilyIPv6,
+			}},
+		}),
+		NewChecker: checkerFor((*MonitorChecker).checkTransaction),
+		Validate:   validateTransaction,
+	})
+	RegisterMonitorType(MonitorType{
+		Name:        "tcp",
+		Label:       "TCP port",
+		Description: "Connects to a port, optionally over TLS, and can send a payload and check the response",
+		Fields: joinFields([]TypeField{
+			{Name: "url", Label: "Address", Kind: FieldString, Required: true, Help: "host:port or tcp://host:port"},
+			{Name: "tcp_send", Label: "Send", Kind: FieldText, Help: "Payload written after connecting"},
+			{Name: "tls_mode", Label: "TLS", Kind: FieldSelect, Options: []string{
+				models.TLSModeNone, models.TLSModeTLS, models.TLSModeStartTLSSMTP, models.TLSModeStartTLSIMAP, models.TLSModeStartTLSPOP3,
+			}},
+		}, keywordFields, tlsFields, familyFields),
+		NewChecker: familyCheckerFor((*MonitorChecker).checkTCP),
+		Validate:   validateTCP,
+	})
+	RegisterMonitorType(MonitorType{
+		Name:        "ping",
+		Label:       "Ping",
+		Description: "Sends ICMP echo requests and checks loss, round-trip time and jitter",
+		Fields: joinFields([]TypeField{
+			{Name: "url", Label: "Host", Kind: FieldString, Required: true},
+			{Name: "ping_count", Label: "Packets", Kind: FieldNumber, Default: 3},
+			{Name: "ping_max_loss", Label: "Max loss (%)", Kind: FieldNumber, Help: "Empty only requires one reply"},
+			{Name: "ping_max_rtt", Label: "Max average RTT (ms)", Kind: FieldNumber},
+			{Name: "ping_max_jitter", Label: "Max jitter (ms)", Kind: FieldNumber},
+			{Name: "ping_privileged", Label: "Raw ICMP", Kind: FieldBoolean, Help: "Requires root or CAP_NET_RAW; UDP pings are used otherwise"},
+		}, familyFields),
+		NewChecker: familyCheckerFor((*MonitorChecker).checkPing),
+		Validate:   validatePing,
+	})
+	RegisterMonitorType(MonitorType{
+		Name:        "dns",
+		Label:       "DNS",
+		Description: "Resolves a name and can compare the answers to an expected set",
+		Fields: []TypeField{
+			{Name: "url", Label: "Name", Kind: FieldString, Required: true},
+			{Name: "dns_record_type", Label: "Record type", Kind: FieldSelect, Options: dnsRecordTypeNames(), Default: "A"},
+			{Name: "dns_resolver", Label: "Resolver", Kind: FieldString, Help: "host[:port]; empty uses the system resolver"},
+			{Name: "dns_expected", Label: "Expected answers", Kind: FieldList, Help: "Compared in any order"},
+		},
+		NewChecker: checkerFor((*MonitorChecker).checkDNS),
+		Validate:   validateDNS,
+	})
+	RegisterMonitorType(MonitorType{
+		Name:        "push",
+		Label:       "Push",
+		Description: "Waits for heartbeats sent to /api/v1/push/:token",
+		Fields: []TypeField{
+			{Name: "push_token", Label: "Token", Kind: FieldString, Help: "Generated when empty"},
+			{Name: "push_grace_period", Label: "Grace period (s)", Kind: FieldNumber, Help: "Allowed past the interval"},
+		},
+		Validate: validatePush,
+	})
+	for _, db := range []struct{ name, label, example, query string }{
+		{"postgres", "PostgreSQL", "postgres://host:5432/db?sslmode=disable", "SELECT 1"},
+		{"mysql", "MySQL", "mysql://host:3306/db", "SELECT 1"},
+		{"redis", "Redis", "redis://host:6379/0 or rediss:// for TLS", "PING"},
+	} {
+		fields := joinFields([]TypeField{
+			{Name: "url", Label: "Connection URL", Kind: FieldString, Required: true, Help: db.example},
+			{Name: "db_query", Label: "Query", Kind: FieldText, Default: db.query},
+		}, credentialFields, assertionFields)
+		check := (*MonitorChecker).checkSQL
+		if db.name == "redis" {
+			fields = joinFields(fields, []TypeField{
+				{Name: "tls_mode", Label: "TLS", Kind: FieldSelect, Options: []string{models.TLSModeNone, models.TLSModeTLS}},
+			}, tlsFields)
+			check = (*MonitorChecker).checkRedis
+		}
+		RegisterMonitorType(MonitorType{
+			Name:        db.name,
+			Label:       db.label,
+			Description: "Connects, runs a query and can assert on the first row",
+			Fields:      fields,
+			NewChecker:  checkerFor(check),
+			Validate:    validateDatabase,
+		})
+	}
+	RegisterMonitorType(MonitorType{
+		Name:        "grpc",
+		Label:       "gRPC",
+		Description: "Calls the standard gRPC health checking service",
+		Fields: joinFields([]TypeField{
+			{Name: "url", Label: "Address", Kind: FieldString, Required: true, Help: "host:port, grpc://host:port or grpcs://host:port for TLS"},
+			{Name: "grpc_service", Label: "Service", Kind: FieldString, Help: "Empty checks the whole server"},
+			{Name: "tls_mode", Label: "TLS", Kind: FieldSelect, Options: []string{models.TLSModeNone, models.TLSModeTLS}},
+		}, tlsFields),
+		NewChecker: checkerFor((*MonitorChecker).checkGRPC),
+		Validate:   validateGRPC,
+	})
+	for _, mail := range []struct{ name, label string }{
+		{StartTLSSMTP, "SMTP"}, {StartTLSIMAP, "IMAP"}, {StartTLSPOP3, "POP3"},
+	} {
+		fields := joinFields([]TypeField{
+			{Name: "url", Label: "Server", Kind: FieldString, Required: true, Help: mail.name + "://host[:port], " + mail.name + "s:// for implicit TLS, or host:port"},
+			{Name: "tls_mode", Label: "TLS", Kind: FieldSelect, Options: []string{models.TLSModeNone, models.TLSModeTLS, models.TLSModeStartTLS}},
+		}, credentialFields)
+		if mail.name == StartTLSSMTP {
+			fields = joinFields(fields, []TypeField{
+				{Name: "smtp_from", Label: "Envelope sender", Kind: FieldString, Help: "Probes MAIL FROM without sending a message"},
+				{Name: "smtp_to", Label: "Envelope recipient", Kind: FieldString, Help: "Probes RCPT TO without sending a message"},
+			})
+		}
+		RegisterMonitorType(MonitorType{
+			Name:        mail.name,
+			Label:       mail.label,
+			Description: "Walks through a " + mail.label + " session: greeting, capabilities, STARTTLS and login",
+			Fields:      joinFields(fields, tlsFields),
+			NewChecker:  checkerFor((*MonitorChecker).checkMail),
+			Validate:    validateMail,
+		})
+	}
+	RegisterMonitorType(MonitorType{
+		Name:        "exec",
+		Label:       "Command",
+		Description: "Runs a Nagios-compatible plugin; needs EXEC_MONITORS on the server",
+		Fields: []TypeField{
+			{Name: "url", Label: "Command", Kind: FieldString, Required: true, Help: "Absolute path; runs without a shell"},
+			{Name: "exec_args", Label: "Arguments", Kind: FieldList},
+			{Name: "max_body_bytes", Label: "Output limit (bytes)", Kind: FieldNumber, Help: "0 uses the default limit"},
+		},
+		NewChecker: checkerFor((*MonitorChecker).checkExec),
+		Validate:   validateExec,
+	})
+}
+
+// checkerFor adapts a check method to a NewChecker function
+func checkerFor(check func(*MonitorChecker, context.Context, *models.MonitorCheck) error) func(*MonitorChecker) Checker {
+	return func(mc *MonitorChecker) Checker {
+		return CheckerFunc(func(ctx context.Context, c *models.MonitorCheck) error {
+			return check(mc, ctx, c)
+		})
+	}
+}
+
+// familyCheckerFor is checkerFor for checks that honour address_family
+func familyCheckerFor(check func(*MonitorChecker, context.Context, *models.MonitorCheck) error) func(*MonitorChecker) Checker {
+	return func(mc *MonitorChecker) Checker {
+		return mc.familyChecker(check)
+	}
+}
+
+// joinFields concatenates field groups into a new slice
+func joinFields(groups ...[]TypeField) []TypeField {
+	var fields []TypeField
+	for _, group := range groups {
+		fields = append(fields, group...)
+	}
+	return fields
+}
+
+func dnsRecordTypeNames() []string {
+	names := make([]string, 0, len(dnsRecordTypes))
+	for name := range dnsRecordTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// httpMethods are the request methods HTTP monitors and steps may send
+var httpMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+var credentialFields = []TypeField{
+	{Name: "auth_username", Label: "Username", Kind: FieldString},
+	{Name: "auth_password", Label: "Password", Kind: FieldSecret},
+}
+
+var authFields = joinFields([]TypeField{
+	{Name: "auth_method", Label: "Authentication", Kind: FieldSelect, Options: []string{models.AuthNone, models.AuthBasic, models.AuthBearer}},
+}, credentialFields, []TypeField{
+	{Name: "auth_token", Label: "Bearer token", Kind: FieldSecret},
+})
+
+var redirectFields = []TypeField{
+	{Name: "follow_redirects", Label: "Follow redirects", Kind: FieldBoolean, Default: true},
+	{Name: "max_redirects", Label: "Max redirects", Kind: FieldNumber, Default: 10},
+}
+
+var familyFields = []TypeField{
+	{Name: "address_family", Label: "Address family", Kind: FieldSelect, Options: []string{
+		models.AddressFamilyAny, models.AddressFamilyIPv4, models.AddressFamilyIPv6, models.AddressFamilyBoth,
+	}, Help: "both checks IPv6 and IPv4, and each must pass"},
+}
+
+var proxyFields = []TypeField{
+	{Name: "proxy_url", Label: "Proxy", Kind: FieldString, Help: "http, https or socks5 URL; \"direct\" skips the server default"},
+}
+
+var tlsFields = []TypeField{
+	{Name: "tls_client_cert", Label: "Client certificate", Kind: FieldText, Help: "PEM"},
+	{Name: "tls_client_key", Label: "Client key", Kind: FieldSecret, Help: "PEM; kept when left empty on update"},
+	{Name: "tls_ca_bundle", Label: "CA bundle", Kind: FieldText, Help: "PEM roots used instead of the system ones"},
+	{Name: "tls_ignore_errors", Label: "Ignore certificate errors", Kind: FieldBoolean},
+}
+
+var keywordFields = []TypeField{
+	{Name: "keyword", Label: "Keyword", Kind: FieldString},
+	{Name: "keyword_regex", Label: "Keyword is a regex", Kind: FieldBoolean},
+	{Name: "keyword_invert", Label: "Fail when the keyword is present", Kind: FieldBoolean},
+	{Name: "max_body_bytes", Label: "Read limit (bytes)", Kind: FieldNumber, Help: "0 uses the default limit"},
+}
+
+var assertionFields = []TypeField{
+	{Name: "json_assertions", Label: "Assertions", Kind: FieldJSON},
+}
+
+var httpFields = joinFields([]TypeField{
+	{Name: "url", Label: "URL", Kind: FieldString, Required: true},
+	{Name: "method", Label: "Method", Kind: FieldSelect, Options: httpMethods, Default: "GET"},
+	{Name: "headers", Label: "Headers", Kind: FieldMap},
+	{Name: "body", Label: "Body", Kind: FieldText},
+}, authFields, []TypeField{
+	{Name: "accepted_status_codes", Label: "Accepted status codes", Kind: FieldString, Default: "200-399"},
+}, redirectFields, []TypeField{
+	{Name: "http_mode", Label: "Mode", Kind: FieldSelect, Options: []string{models.HTTPModeStatus, models.HTTPModeJSON, models.HTTPModeContent}},
+}, keywordFields, assertionFields, []TypeField{
+	{Name: "content_ignore_regex", Label: "Ignore patterns", Kind: FieldList, Help: "Content mode: regexes removed before comparing"},
+	{Name: "content_ignore_selectors", Label: "Ignore selectors", Kind: FieldList, Help: "Content mode: elements removed before comparing, e.g. div.ad"},
+}, proxyFields, tlsFields, familyFields)
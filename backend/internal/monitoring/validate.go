@@ -0,0 +1,321 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"uptime-monitor/internal/models"
+)
+
+// validateOptions checks the type's select fields against their options
+func validateOptions(fields []TypeField, monitor models.Monitor) FieldErrors {
+	raw, err := json.Marshal(monitor)
+	if err != nil {
+		return FieldErrors{{Field: "type", Message: err.Error()}}
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return FieldErrors{{Field: "type", Message: err.Error()}}
+	}
+
+	var errs FieldErrors
+	for _, field := range fields {
+		if field.Kind != FieldSelect {
+			continue
+		}
+		value, _ := values[field.Name].(string)
+		if !containsString(field.Options, value) {
+			errs.Add(field.Name, "unsupported value %q, expected one of %s", value, quoteOptions(field.Options))
+		}
+	}
+	return errs
+}
+
+func quoteOptions(options []string) string {
+	quoted := make([]string, len(options))
+	for i, option := range options {
+		quoted[i] = fmt.Sprintf("%q", option)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func validateHTTP(monitor models.Monitor) FieldErrors {
+	var errs FieldErrors
+	u, err := url.Parse(monitor.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs.Add("url", "must be an http:// or https:// URL")
+	}
+
+	validateRequest(monitor, &errs)
+	validateKeyword(monitor, &errs)
+	if monitor.HTTPMode == models.HTTPModeJSON && len(monitor.JSONAssertions) == 0 {
+		errs.Add("json_assertions", "json mode requires at least one assertion")
+	}
+	validateAssertions("json_assertions", monitor.JSONAssertions, &errs)
+	for i, pattern := range monitor.ContentIgnoreRegex {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs.Add(fmt.Sprintf("content_ignore_regex[%d]", i), "%v", err)
+		}
+	}
+	for i, selector := range monitor.ContentIgnoreSelectors {
+		if err := ValidateContentSelector(selector); err != nil {
+			errs.Add(fmt.Sprintf("content_ignore_selectors[%d]", i), "%v", err)
+		}
+	}
+	validateProxy(monitor, &errs)
+	validateTLS(monitor, &errs)
+	return errs
+}
+
+func validateTransaction(monitor models.Monitor) FieldErrors {
+	var errs FieldErrors
+	if len(monitor.TransactionSteps) == 0 {
+		errs.Add("transaction_steps", "transaction monitors require at least one step")
+	}
+	for i, step := range monitor.TransactionSteps {
+		validateStep(fmt.Sprintf("transaction_steps[%d]", i), step, &errs)
+	}
+
+	validateHeaders("headers", monitor.Headers, &errs)
+	validateAuth(monitor, &errs)
+	validateRedirects(monitor, &errs)
+	validateProxy(monitor, &errs)
+	validateTLS(monitor, &errs)
+	return errs
+}
+
+func validateStep(prefix string, step models.TransactionStep, errs *FieldErrors) {
+	field := func(name string) string { return prefix + "." + name }
+
+	if step.URL == "" {
+		errs.Add(field("url"), "url is required")
+	}
+	if !containsString(httpMethods, step.Method) {
+		errs.Add(field("method"), "unsupported method: %s", step.Method)
+	}
+	validateHeaders(field("headers"), step.Headers, errs)
+	if err := ValidateStatusCodes(step.AcceptedStatusCodes); err != nil {
+		errs.Add(field("accepted_status_codes"), "%v", err)
+	}
+	if step.KeywordRegex {
+		if _, err := regexp.Compile(step.Keyword); err != nil {
+			errs.Add(field("keyword"), "invalid keyword regex: %v", err)
+		}
+	}
+	validateAssertions(field("json_assertions"), step.JSONAssertions, errs)
+
+	for i, capture := range step.Captures {
+		name := fmt.Sprintf("%s[%d]", field("captures"), i)
+		if !VariableName.MatchString(capture.Name) {
+			errs.Add(name+".name", "must be letters, digits or underscores")
+		}
+		if (capture.Regex == "") == (capture.Path == "") {
+			errs.Add(name, "set exactly one of regex or path")
+		} else if capture.Regex != "" {
+			if _, err := regexp.Compile(capture.Regex); err != nil {
+				errs.Add(name+".regex", "invalid regex: %v", err)
+			}
+		} else if err := ValidateJSONPath(capture.Path); err != nil {
+			errs.Add(name+".path", "%v", err)
+		}
+	}
+}
+
+func validateTCP(monitor models.Monitor) FieldErrors {
+	var errs FieldErrors
+	if _, err := tcpAddress(monitor.URL); err != nil {
+		errs.Add("url", "%v", err)
+	}
+	validateKeyword(monitor, &errs)
+	validateTLS(monitor, &errs)
+	return errs
+}
+
+func validatePing(monitor models.Monitor) FieldErrors {
+	var errs FieldErrors
+	u, err := url.Parse(monitor.URL)
+	if err != nil || (u.Hostname() == "" && u.Path == "") {
+		errs.Add("url", "must be a host name or address")
+	}
+	// Packets go out once a second and all must be sent within the timeout
+	timeout := monitor.Timeout
+	if timeout <= 0 {
+		timeout = int(defaultCheckTimeout.Seconds())
+	}
+	if monitor.PingCount < 1 || monitor.PingCount > 100 {
+		errs.Add("ping_count", "must be between 1 and 100")
+	} else if monitor.PingCount > timeout {
+		errs.Add("ping_count", "%d packets at one per second do not fit in the %ds timeout", monitor.PingCount, timeout)
+	}
+	if monitor.PingMaxLoss != nil && (*monitor.PingMaxLoss < 0 || *monitor.PingMaxLoss > 100) {
+		errs.Add("ping_max_loss", "must be between 0 and 100")
+	}
+	if monitor.PingMaxRTT < 0 {
+		errs.Add("ping_max_rtt", "must not be negative")
+	}
+	if monitor.PingMaxJitter < 0 {
+		errs.Add("ping_max_jitter", "must not be negative")
+	}
+	return errs
+}
+
+func validateDNS(monitor models.Monitor) FieldErrors {
+	var errs FieldErrors
+	if dnsQueryName(monitor.URL) == "" {
+		errs.Add("url", "no DNS name specified")
+	}
+	return errs
+}
+
+func validatePush(monitor models.Monitor) FieldErrors {
+	var errs FieldErrors
+	if monitor.PushGracePeriod < 0 {
+		errs.Add("push_grace_period", "must not be negative")
+	}
+	return errs
+}
+
+// validateDatabase checks the database monitors' connection URLs and
+// assertions
+func validateDatabase(monitor models.Monitor) FieldErrors {
+	var errs FieldErrors
+	u, err := url.Parse(monitor.URL)
+	if err != nil || u.Host == "" {
+		errs.Add("url", "must be a connection URL like %s://host/", monitor.Type)
+	}
+	validateAssertions("json_assertions", monitor.JSONAssertions, &errs)
+	validateTLS(monitor, &errs)
+	return errs
+}
+
+func validateGRPC(monitor models.Monitor) FieldErrors {
+	var errs FieldErrors
+	address, _, err := grpcTarget(monitor.URL)
+	if err == nil {
+		_, _, err = net.SplitHostPort(address)
+	}
+	if err != nil {
+		errs.Add("url", "%v", err)
+	}
+	validateTLS(monitor, &errs)
+	return errs
+}
+
+func validateMail(monitor models.Monitor) FieldErrors {
+	var errs FieldErrors
+	if _, _, err := mailTarget(monitor.URL, monitor.Type); err != nil {
+		errs.Add("url", "%v", err)
+	}
+	if strings.ContainsAny(monitor.SMTPFrom, "<>\r\n") {
+		errs.Add("smtp_from", "must be a plain address")
+	}
+	if strings.ContainsAny(monitor.SMTPTo, "<>\r\n") {
+		errs.Add("smtp_to", "must be a plain address")
+	}
+	// Credentials are sent inside protocol lines
+	if strings.ContainsAny(monitor.AuthUsername, "\r\n") {
+		errs.Add("auth_username", "must not contain line breaks")
+	}
+	if strings.ContainsAny(monitor.AuthPassword, "\r\n") {
+		errs.Add("auth_password", "must not contain line breaks")
+	}
+	validateTLS(monitor, &errs)
+	return errs
+}
+
+func validateExec(monitor models.Monitor) FieldErrors {
+	var errs FieldErrors
+	if !filepath.IsAbs(monitor.URL) {
+		errs.Add("url", "must be an absolute command path")
+	}
+	if monitor.MaxBodyBytes < 0 {
+		errs.Add("max_body_bytes", "must not be negative")
+	}
+	return errs
+}
+
+// validateRequest checks the HTTP request settings
+func validateRequest(monitor models.Monitor, errs *FieldErrors) {
+	validateHeaders("headers", monitor.Headers, errs)
+	validateAuth(monitor, errs)
+	if err := ValidateStatusCodes(monitor.AcceptedStatusCodes); err != nil {
+		errs.Add("accepted_status_codes", "%v", err)
+	}
+	validateRedirects(monitor, errs)
+}
+
+func validateHeaders(field string, headers models.StringMap, errs *FieldErrors) {
+	for name, value := range headers {
+		if name == "" || strings.ContainsAny(name, " :\r\n\t") {
+			errs.Add(field, "invalid header name: %q", name)
+		} else if strings.ContainsAny(value, "\r\n") {
+			errs.Add(field, "invalid value for header %s", name)
+		}
+	}
+}
+
+func validateAuth(monitor models.Monitor, errs *FieldErrors) {
+	switch monitor.AuthMethod {
+	case models.AuthBasic:
+		if monitor.AuthUsername == "" {
+			errs.Add("auth_username", "basic auth requires a username")
+		}
+	case models.AuthBearer:
+		if monitor.AuthToken == "" {
+			errs.Add("auth_token", "bearer auth requires a token")
+		}
+	}
+}
+
+func validateRedirects(monitor models.Monitor, errs *FieldErrors) {
+	if monitor.MaxRedirects < 1 || monitor.MaxRedirects > 30 {
+		errs.Add("max_redirects", "must be between 1 and 30")
+	}
+}
+
+// validateKeyword checks the response assertion shared by HTTP and TCP
+func validateKeyword(monitor models.Monitor, errs *FieldErrors) {
+	if monitor.MaxBodyBytes < 0 {
+		errs.Add("max_body_bytes", "must not be negative")
+	}
+	if monitor.KeywordRegex {
+		if _, err := regexp.Compile(monitor.Keyword); err != nil {
+			errs.Add("keyword", "invalid keyword regex: %v", err)
+		}
+	}
+}
+
+func validateAssertions(field string, assertions []models.JSONAssertion, errs *FieldErrors) {
+	for i, assertion := range assertions {
+		if err := ValidateJSONAssertion(assertion); err != nil {
+			errs.Add(fmt.Sprintf("%s[%d]", field, i), "%v", err)
+		}
+	}
+}
+
+func validateProxy(monitor models.Monitor, errs *FieldErrors) {
+	if monitor.ProxyURL == "" || monitor.ProxyURL == ProxyDirect {
+		return
+	}
+	if err := ValidateProxyURL(monitor.ProxyURL); err != nil {
+		errs.Add("proxy_url", "%v", err)
+	}
+}
+
+// validateTLS checks the client certificate and CA bundle
+func validateTLS(monitor models.Monitor, errs *FieldErrors) {
+	if _, err := clientCertificates(monitor); err != nil {
+		field := "tls_client_cert"
+		if monitor.TLSClientCert != "" && monitor.TLSClientKey == "" {
+			field = "tls_client_key"
+		}
+		errs.Add(field, "%v", err)
+	}
+	if _, err := caBundle(monitor.TLSCABundle); err != nil {
+		errs.Add("tls_ca_bundle", "%v", err)
+	}
+}
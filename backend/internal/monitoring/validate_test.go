@@ -0,0 +1,72 @@
+package monitoring
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"uptime-monitor/internal/models"
+)
+
+func TestValidateMonitor(t *testing.T) {
+	httpMonitor := func(change func(*models.Monitor)) models.Monitor {
+		m := models.Monitor{
+			Type: "http", URL: "https://example.com", Method: "GET",
+			AcceptedStatusCodes: "200-399", MaxRedirects: 10,
+		}
+		change(&m)
+		return m
+	}
+	loss := 150.0
+
+	tests := []struct {
+		name    string
+		monitor models.Monitor
+		fields  []string
+	}{
+		{"valid http", httpMonitor(func(m *models.Monitor) {}), nil},
+		{"unknown type", models.Monitor{Type: "gopher", Method: "FETCH"}, []string{"type"}},
+		{"bad url", httpMonitor(func(m *models.Monitor) { m.URL = "example.com" }), []string{"url"}},
+		{"bad method", httpMonitor(func(m *models.Monitor) { m.Method = "FETCH" }), []string{"method"}},
+		{"bad http mode", httpMonitor(func(m *models.Monitor) { m.HTTPMode = "xml" }), []string{"http_mode"}},
+		{"json mode without assertions", httpMonitor(func(m *models.Monitor) { m.HTTPMode = models.HTTPModeJSON }), []string{"json_assertions"}},
+		{"bad selector", httpMonitor(func(m *models.Monitor) { m.ContentIgnoreSelectors = models.StringList{"div", "> p"} }), []string{"content_ignore_selectors[1]"}},
+		{"basic auth without username", httpMonitor(func(m *models.Monitor) { m.AuthMethod = models.AuthBasic }), []string{"auth_username"}},
+		{"several errors", httpMonitor(func(m *models.Monitor) {
+			m.MaxRedirects = 50
+			m.KeywordRegex = true
+			m.Keyword = "("
+		}), []string{"max_redirects", "keyword"}},
+		{"address family ignored by dns", models.Monitor{Type: "dns", URL: "example.com", DNSRecordType: "A", AddressFamily: "both"}, nil},
+		{"bad record type", models.Monitor{Type: "dns", URL: "example.com", DNSRecordType: "PTR"}, []string{"dns_record_type"}},
+		{"starttls for tcp", models.Monitor{Type: "tcp", URL: "localhost:25", TLSMode: models.TLSModeStartTLS}, []string{"tls_mode"}},
+		{"ping thresholds", models.Monitor{Type: "ping", URL: "localhost", PingCount: 0, PingMaxLoss: &loss}, []string{"ping_count", "ping_max_loss"}},
+		{"ping count within timeout", models.Monitor{Type: "ping", URL: "localhost", PingCount: 10, Timeout: 10}, nil},
+		{"ping count beyond timeout", models.Monitor{Type: "ping", URL: "localhost", PingCount: 100, Timeout: 30}, []string{"ping_count"}},
+		{"ping count beyond default timeout", models.Monitor{Type: "ping", URL: "localhost", PingCount: 31}, []string{"ping_count"}},
+		{"transaction without steps", models.Monitor{Type: "transaction", MaxRedirects: 10}, []string{"transaction_steps"}},
+		{"transaction step", models.Monitor{Type: "transaction", MaxRedirects: 10, TransactionSteps: models.TransactionSteps{
+			{URL: "https://example.com", Method: "GET", AcceptedStatusCodes: "200", Captures: []models.StepCapture{{Name: "a-b", Regex: "x"}}},
+		}}, []string{"transaction_steps[0].captures[0].name"}},
+		{"line break in mail login", models.Monitor{Type: "pop3", URL: "pop3://mail.example.com", AuthUsername: "me", AuthPassword: "x\r\nDELE 1"}, []string{"auth_password"}},
+		{"relative exec path", models.Monitor{Type: "exec", URL: "check.sh"}, []string{"url"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMonitor(tt.monitor)
+			var fields []string
+			if err != nil {
+				var errs FieldErrors
+				if !errors.As(err, &errs) {
+					t.Fatalf("error is not a FieldErrors: %v", err)
+				}
+				for _, fe := range errs {
+					fields = append(fields, fe.Field)
+				}
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("failing fields %v, want %v (%v)", fields, tt.fields, err)
+			}
+		})
+	}
+}
@@ -254,6 +254,9 @@ func (sm *ShoutrrrManager) buildMessage(monitor models.Monitor, check models.Mon
 	case models.EventRecovery:
 		emoji = "🔄"
 		title = "Monitor Recovered"
+	case models.EventContentChanged:
+		emoji = "📝"
+		title = "Content Changed"
 	default:
 		emoji = "ℹ️"
 		title = "Monitor Alert"
@@ -277,6 +280,17 @@ func (sm *ShoutrrrManager) buildMessage(monitor models.Monitor, check models.Mon
 		sb.WriteString(fmt.Sprintf("Message: %s\n", check.Message))
 	}
 
+	if event == models.EventSSLExpiringSoon && check.CertExpiresAt != nil && check.CertDaysRemaining != nil {
+		sb.WriteString(fmt.Sprintf("Certificate Expires: %s (%d days)\n", check.CertExpiresAt.Format("2006-01-02"), *check.CertDaysRemaining))
+		if check.CertIssuer != "" {
+			sb.WriteString(fmt.Sprintf("Issuer: %s\n", check.CertIssuer))
+		}
+	}
+
+	if event == models.EventContentChanged && check.ContentDiff != "" {
+		sb.WriteString(fmt.Sprintf("Changes:\n%s\n", check.ContentDiff))
+	}
+
 	sb.WriteString(fmt.Sprintf("Checked: %s", check.CheckedAt.Format("2006-01-02 15:04:05 MST")))
 
 	return sb.String()
@@ -284,6 +298,21 @@ func (sm *ShoutrrrManager) buildMessage(monitor models.Monitor, check models.Mon
 
 // DetermineEvent determines what notification event should be triggered based on status change
 func DetermineEvent(currentStatus, previousStatus string, responseTime int, slowThreshold int) models.NotificationEvent {
+	// A degraded monitor is still reachable, so it alerts like one that is up
+	if currentStatus == "degraded" {
+		currentStatus = "up"
+	}
+	if previousStatus == "degraded" {
+		previousStatus = "up"
+	}
+	// A check that timed out failed, so it alerts like one that is down
+	if currentStatus == "timeout" {
+		currentStatus = "down"
+	}
+	if previousStatus == "timeout" {
+		previousStatus = "down"
+	}
+
 	// Status changed from down to up
 	if previousStatus == "down" && currentStatus == "up" {
 		return models.EventRecovery
@@ -1,37 +1,85 @@
 package websocket
 
 import (
+	"context"
+	"encoding/json"
 	"log"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"uptime-monitor/internal/auth"
 
 	"github.com/gorilla/websocket"
 )
 
+// tokenSubprotocol is offered by browser clients that cannot set headers,
+// followed by the JWT itself: new WebSocket(url, ["bearer", token])
+const tokenSubprotocol = "bearer"
+
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
-		return true // Allow all origins for development
+		// Connections authenticate with an explicit token rather than
+		// cookies, so cross-origin requests cannot ride on a session
+		return true
 	},
+	Subprotocols: []string{tokenSubprotocol},
+}
+
+// Event types pushed to connected clients
+const (
+	EventCheck          = "check"
+	EventStatusChange   = "status_change"
+	EventMonitorCreated = "monitor_created"
+	EventMonitorUpdated = "monitor_updated"
+	EventMonitorDeleted = "monitor_deleted"
+)
+
+// Event is a typed message sent to WebSocket clients as JSON
+type Event struct {
+	Type      string      `json:"type"`
+	MonitorID int         `json:"monitor_id"`
+	Data      interface{} `json:"data,omitempty"`
+	Tags      []string    `json:"-"` // tags of the monitor, used for subscription matching
 }
 
 type Hub struct {
 	clients    map[*Client]bool
-	broadcast  chan []byte
+	broadcast  chan outbound
+	reply      chan outbound
 	register   chan *Client
 	unregister chan *Client
+	quit       chan struct{}  // closed by Close
+	stopped    chan struct{}  // closed once Run has returned
+	writers    sync.WaitGroup // running writePumps
 }
 
 type Client struct {
-	hub  *Hub
-	conn *websocket.Conn
-	send chan []byte
+	hub           *Hub
+	conn          *websocket.Conn
+	send          chan []byte
+	userID        int
+	expiresAt     time.Time
+	subscriptions *subscriptions
+}
+
+// outbound is a message queued for delivery. Messages without an event are
+// delivered to every client; replies are delivered to a single client.
+type outbound struct {
+	event   *Event
+	client  *Client
+	payload []byte
 }
 
 func NewHub() *Hub {
 	return &Hub{
 		clients:    make(map[*Client]bool),
-		broadcast:  make(chan []byte),
+		broadcast:  make(chan outbound),
+		reply:      make(chan outbound),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
+		quit:       make(chan struct{}),
+		stopped:    make(chan struct{}),
 	}
 }
 
@@ -40,7 +88,7 @@ func (h *Hub) Run() {
 		select {
 		case client := <-h.register:
 			h.clients[client] = true
-			log.Println("Client connected")
+			log.Printf("Client connected (user ID: %d)", client.userID)
 
 		case client := <-h.unregister:
 			if _, ok := h.clients[client]; ok {
@@ -49,21 +97,78 @@ func (h *Hub) Run() {
 				log.Println("Client disconnected")
 			}
 
-		case message := <-h.broadcast:
+		case msg := <-h.reply:
+			if _, ok := h.clients[msg.client]; ok {
+				h.deliver(msg.client, msg.payload)
+			}
+
+		case msg := <-h.broadcast:
 			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
+				if !client.authorized() {
 					close(client.send)
 					delete(h.clients, client)
+					continue
+				}
+				if msg.event != nil && !client.subscriptions.matches(msg.event) {
+					continue
 				}
+				h.deliver(client, msg.payload)
+			}
+
+		case <-h.quit:
+			// Closing send makes each writePump send a close frame
+			for client := range h.clients {
+				close(client.send)
+				delete(h.clients, client)
 			}
+			close(h.stopped)
+			return
 		}
 	}
 }
 
-func (h *Hub) HandleWebSocket() http.HandlerFunc {
+// Close sends every client a close frame and stops the hub. It waits until
+// the frames are written or ctx is done.
+func (h *Hub) Close(ctx context.Context) {
+	close(h.quit)
+	<-h.stopped
+
+	written := make(chan struct{})
+	go func() {
+		h.writers.Wait()
+		close(written)
+	}()
+	select {
+	case <-written:
+	case <-ctx.Done():
+	}
+	log.Println("WebSocket hub stopped")
+}
+
+// deliver queues a message for a client, dropping clients that cannot keep up
+func (h *Hub) deliver(client *Client, payload []byte) {
+	select {
+	case client.send <- payload:
+	default:
+		close(client.send)
+		delete(h.clients, client)
+	}
+}
+
+func (h *Hub) HandleWebSocket(authService *auth.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		tokenString := requestToken(r)
+		if tokenString == "" {
+			http.Error(w, "Authentication token required", http.StatusUnauthorized)
+			return
+		}
+
+		claims, err := authService.ValidateToken(tokenString)
+		if err != nil {
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
 		conn, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
 			log.Println("WebSocket upgrade error:", err)
@@ -71,33 +176,84 @@ func (h *Hub) HandleWebSocket() http.HandlerFunc {
 		}
 
 		client := &Client{
-			hub:  h,
-			conn: conn,
-			send: make(chan []byte, 256),
+			hub:           h,
+			conn:          conn,
+			send:          make(chan []byte, 256),
+			subscriptions: newSubscriptions(),
+		}
+		if userID, ok := (*claims)["user_id"].(float64); ok {
+			client.userID = int(userID)
+		}
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			client.expiresAt = exp.Time
 		}
 
-		client.hub.register <- client
+		select {
+		case h.register <- client:
+		case <-h.stopped:
+			conn.Close()
+			return
+		}
 
+		h.writers.Add(1)
 		go client.writePump()
 		go client.readPump()
 	}
 }
 
+// requestToken extracts the JWT from the query string, the subprotocol list
+// or the Authorization header, in that order
+func requestToken(r *http.Request) string {
+	if token := r.URL.Query().Get("token"); token != "" {
+		return token
+	}
+
+	protocols := websocket.Subprotocols(r)
+	for i, protocol := range protocols {
+		if protocol == tokenSubprotocol && i+1 < len(protocols) {
+			return protocols[i+1]
+		}
+	}
+
+	if authHeader := r.Header.Get("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+		return authHeader[7:]
+	}
+
+	return ""
+}
+
+// authorized reports whether the client's token is still valid
+func (c *Client) authorized() bool {
+	return c.expiresAt.IsZero() || time.Now().Before(c.expiresAt)
+}
+
 func (c *Client) readPump() {
 	defer func() {
-		c.hub.unregister <- c
+		select {
+		case c.hub.unregister <- c:
+		case <-c.hub.stopped:
+		}
 		c.conn.Close()
 	}()
 
+	c.conn.SetReadLimit(maxMessageSize)
+
 	for {
-		_, _, err := c.conn.ReadMessage()
+		_, message, err := c.conn.ReadMessage()
 		if err != nil {
 			break
 		}
+
+		select {
+		case c.hub.reply <- outbound{client: c, payload: c.handleMessage(message)}:
+		case <-c.hub.stopped:
+			return
+		}
 	}
 }
 
 func (c *Client) writePump() {
+	defer c.hub.writers.Done()
 	defer c.conn.Close()
 
 	for {
@@ -116,5 +272,23 @@ func (c *Client) writePump() {
 }
 
 func (h *Hub) Broadcast(message []byte) {
-	h.broadcast <- message
+	h.send(outbound{payload: message})
+}
+
+// Publish encodes an event as JSON and sends it to subscribed clients
+func (h *Hub) Publish(event Event) {
+	message, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to encode WebSocket event %s: %v", event.Type, err)
+		return
+	}
+	h.send(outbound{event: &event, payload: message})
+}
+
+// send queues a broadcast, dropping it once the hub has stopped
+func (h *Hub) send(msg outbound) {
+	select {
+	case h.broadcast <- msg:
+	case <-h.stopped:
+	}
 }
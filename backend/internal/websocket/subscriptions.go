@@ -0,0 +1,136 @@
+package websocket
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// maxMessageSize limits inbound client messages
+const maxMessageSize = 8192
+
+// Inbound client actions
+const (
+	ActionSubscribe   = "subscribe"
+	ActionUnsubscribe = "unsubscribe"
+)
+
+// ClientMessage is a subscription request sent by a client, e.g.
+// {"action":"subscribe","monitor_ids":[1,2],"tags":["production"]}
+type ClientMessage struct {
+	Action     string   `json:"action"`
+	All        bool     `json:"all"`
+	MonitorIDs []int    `json:"monitor_ids"`
+	Tags       []string `json:"tags"`
+}
+
+// SubscriptionState is sent back to the client after every request
+type SubscriptionState struct {
+	Type       string   `json:"type"`
+	All        bool     `json:"all"`
+	MonitorIDs []int    `json:"monitor_ids"`
+	Tags       []string `json:"tags"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// subscriptions tracks which monitors and tags a client asked for. It is
+// written from the client's read loop and read from the hub loop.
+type subscriptions struct {
+	mu       sync.RWMutex
+	all      bool
+	monitors map[int]bool
+	tags     map[string]bool
+}
+
+func newSubscriptions() *subscriptions {
+	return &subscriptions{
+		monitors: make(map[int]bool),
+		tags:     make(map[string]bool),
+	}
+}
+
+// matches reports whether an event belongs to a subscribed monitor or tag
+func (s *subscriptions) matches(event *Event) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.all || s.monitors[event.MonitorID] {
+		return true
+	}
+	for _, tag := range event.Tags {
+		if s.tags[normalizeTag(tag)] {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *subscriptions) apply(msg ClientMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscribe := msg.Action == ActionSubscribe
+	if msg.All {
+		s.all = subscribe
+	}
+	for _, id := range msg.MonitorIDs {
+		if subscribe {
+			s.monitors[id] = true
+		} else {
+			delete(s.monitors, id)
+		}
+	}
+	for _, tag := range msg.Tags {
+		tag = normalizeTag(tag)
+		if tag == "" {
+			continue
+		}
+		if subscribe {
+			s.tags[tag] = true
+		} else {
+			delete(s.tags, tag)
+		}
+	}
+}
+
+func (s *subscriptions) state() SubscriptionState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state := SubscriptionState{
+		Type:       "subscriptions",
+		All:        s.all,
+		MonitorIDs: []int{},
+		Tags:       []string{},
+	}
+	for id := range s.monitors {
+		state.MonitorIDs = append(state.MonitorIDs, id)
+	}
+	for tag := range s.tags {
+		state.Tags = append(state.Tags, tag)
+	}
+	return state
+}
+
+// handleMessage applies a client request and returns the encoded reply
+func (c *Client) handleMessage(message []byte) []byte {
+	var msg ClientMessage
+	var errMsg string
+
+	if err := json.Unmarshal(message, &msg); err != nil {
+		errMsg = "invalid message: " + err.Error()
+	} else if msg.Action != ActionSubscribe && msg.Action != ActionUnsubscribe {
+		errMsg = "unknown action: " + msg.Action
+	} else {
+		c.subscriptions.apply(msg)
+	}
+
+	state := c.subscriptions.state()
+	state.Error = errMsg
+	reply, _ := json.Marshal(state)
+	return reply
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}